@@ -19,25 +19,115 @@ package cmd
 import "github.com/lithammer/dedent"
 
 var (
-	WavemanShort string = "waveman generates stylized visual waveforms from mp3 files. Comes with a box painter and a line painter, but can be extended to with other painters easily."
+	WavemanShort string = "waveman generates stylized visual waveforms from mp3, wav, flac, and ogg files. Comes with a box painter and a line painter, but can be extended to with other painters easily."
 
 	WavemanLong string = dedent.Dedent(`
-		Generate SVG waveforms for one or more mp3 files.
+		Generate SVG waveforms for one or more mp3, wav, flac, or ogg files.
 
 		Prints SVG to stdout when not --output is not specified. When passing in a
-		directory, will create SVG files named by the mp3 source files. When the
-		--recursive flag is used, *all* mp3 files below the path are used and SVG files
-		are colocated with the source mp3 files.
+		directory, will create SVG files named by the audio source files. When the
+		--recursive flag is used, *all* mp3, wav, flac, ogg, and opus files below the
+		path are used and SVG files are colocated with the source audio files. Files
+		with other or missing extensions are included if their contents are recognized.
+		The decoder is chosen by the file's magic bytes, falling back to its extension,
+		and can be forced with --format. WAV files may contain 8, 16, 24, or 32 bit
+		integer PCM or 32 or 64 bit float samples, with any number of channels. Ogg
+		files may contain either Vorbis or Opus streams.
+
+		Use "-f -" to read audio from stdin, e.g., in shell pipelines. Since stdin
+		cannot be seeked, the audio is buffered in memory before being transformed,
+		unless --streaming is used, which aggregates the audio in a single pass.
+		Without --output, the SVG is printed to stdout, otherwise it is written to
+		stdin.svg in the working directory.
 		
 		You can configure the sample decoder/transformer in various ways: The number of
 		chunks to be passed down to the painter can be set with --chunks (or -n). The
-		number must be non-negative. The aggregation function by default uses
-		Root-Mean-Square ("rms") for the samples in each chunk. This mimmicks the way
-		metering in most DAWs would. Instead, you can also choose "avg", "max",
-		"mean-square", or "rounded-avg". The last mode is particularly nice if you don't
-		like large floating point numbers in you SVG code, rounding to 3 digits by
-		default.
+		number must be non-negative. Alternatively, --chunk-duration sets the duration
+		of audio per chunk, e.g., "500ms", and derives the number of chunks from the
+		length of the audio, such that longer tracks produce more chunks. The
+		aggregation function by default uses Root-Mean-Square ("rms") for the samples
+		in each chunk. This mimmicks the way metering in most DAWs would. Instead, you
+		can also choose "avg", "max", "mean-square", or "rounded-avg". The last mode is
+		particularly nice if you don't like large floating point numbers in you SVG
+		code, rounding to 3 digits by default.
+
+		Additional aggregators can be computed in the same pass with --layers, e.g.,
+		"--layers peak,rms", which the box painter draws as stacked layers from back to
+		front, colored by --layer-colors. "peak" is an alias of "max".
+
+		All aggregators but "min-max" operate on the absolute value of the signal, such
+		that waveforms are symmetric. "min-max" instead yields the signed maximum and
+		minimum of each chunk, which the line and sweep painters draw as independent
+		upper and lower envelopes, like an oscilloscope would.
+
+		To make waveforms reflect perceived loudness, use "lufs-momentary" or
+		"lufs-short-term", which measure the K-weighted loudness of ITU-R BS.1770 over
+		a sliding window of 400ms or 3s, respectively, ending at each chunk. Loudness
+		aggregators read all samples, so downsampling does not apply. Combine them with
+		"--scale db" to draw the loudness in LUFS.
+
+		Blocks are linear in the amplitude of the signal by default, such that quiet
+		passages are barely visible. Use "--scale db" to map levels between --db-floor,
+		-60 dBFS by default, and 0 dBFS to the height of the waveform, or "--scale power"
+		to raise amplitudes to --gamma, e.g., "--gamma 0.5". Scaling happens before
+		--normalize and windowing.
+
+		--normalize scales each file individually to the full height, such that a quiet
+		track looks as loud as a loud one. To compare tracks, e.g., of an album, use
+		--normalize-global, which transforms all files in a first pass to find the
+		range of the entire batch, and normalizes all files against it in a second
+		pass. A single file looks the same with either flag.
+
+		With --bands, the spectrum of each chunk is split into a low band below 250 Hz,
+		a mid band, and a high band above 4 kHz. The box painter colors each box by
+		mixing --band-colors, red, green, and blue by default, weighted by the energy of
+		each band, as in DJ software. Requires the audio's sample rate to be known.
+
+		By default, both channels are summed to mono before aggregation, such that
+		out-of-phase material cancels out. Use --channels to aggregate only the "left"
+		or "right" channel, the "mid" or "side" signal, or "split-stereo", for which
+		the box and line painters draw the left channel in the upper half and the right
+		channel mirrored in the lower half of the canvas.
 		
+		Painters accept gradients and color stops wherever they take a color, using a
+		CSS-like syntax: "linear-gradient(to right, #f60, #90f)" spans the entire canvas,
+		e.g., horizontally across all boxes, "linear-gradient(to top, #0a0, #f00)" colors
+		shapes by their height, and "radial-gradient(#fff, #000)" spreads out from the
+		canvas's center. Stops take an optional offset, e.g., "#ff0 60%". Gradients are
+		written to the SVG's <defs>. "stops(#0a0, #ff0 60%, #f00)" instead maps the
+		value of each box, bar, or dot to a color between the stops, which must be hex
+		colors. Painters drawing a single shape map the highest value.
+
+		For web players, --progress adds a second copy of the waveform on top, drawn in
+		--played-color and clipped to the played part of the track from the left. With
+		"--progress css", the played part follows the CSS custom property --progress
+		in [0,1], which the player sets on the SVG element as the track progresses. With
+		"--progress animate", an SVG <animate> grows the played part over the duration
+		of the audio, which the player can keep in sync with setCurrentTime().
+
+		For social clips, --animation animates the box, line, wave, and sweep painters
+		with SMIL: "grow" grows each box or path from flat to its final shape once, and
+		"pulse" repeatedly flattens and restores it. --animation-duration sets the
+		duration of each shape's animation, --animation-easing its timing function, and
+		--animation-stagger the delay between consecutive shapes, such that boxes grow
+		in from left to right.
+
+		To share waveforms where SVG is not supported, "--output-format png" renders
+		them to PNG images with a built-in rasterizer instead, written to files with the
+		.png extension. The image's size follows from the waveform's canvas at --dpi,
+		96 by default, such that one unit is one pixel, or is set with --png-width and
+		--png-height in pixels. Animations are rendered in their final state, and
+		--progress only shows the unplayed waveform. The flag is named --output-format,
+		since --format sets the format of the input.
+
+		For players rendering waveforms client-side, e.g., wavesurfer.js or peaks.js,
+		"--output-format json" writes only the transformed data in the JSON format of
+		BBC's audiowaveform, and "--output-format dat" in its binary format, to files
+		with the .json or .dat extension. Each chunk is a pair of the minimum and maximum,
+		mirrored unless using the "min-max" aggregator, quantized to --bits, either 8 or
+		16. With --channels split-stereo, both channels are written. The painter and its
+		flags are ignored.
+
 		You can improve performance of the waveman by aggressively downsampling the
 		audio file. We tested this out and found that using full resolution for the
 		aggregation of samples yields minimum visual changes to the audio file, compared
@@ -68,6 +158,9 @@ var (
 		# Create a line waveform with 32 sample points for a single mp3
 		waveman line --chunks 32 -f audio.mp3
 
+		# Create a box waveform with a horizontal gradient
+		waveman box --color "linear-gradient(to right, #f60, #90f)" -f audio.mp3
+
 		# Create a red box waveform with 50 blocks at 1/8 downsampling factor
 		waveman box --chunks 50 --fill-color red --downsampling-factor 8 -f audio.mp3
 
@@ -82,5 +175,14 @@ var (
 			--closed --inverted --spread 50 \
 			--downsampling-factor 64 --downsampling-mode head \
 			-f audio.mp3
+
+		# Create a 1200 pixels wide PNG image of a box waveform
+		waveman box --output-format png --png-width 1200 -f audio.mp3 > audio.png
+
+		# Write 1000 peaks for wavesurfer.js
+		waveman box --chunks 1000 --aggregator min-max --output-format json -f audio.mp3 > audio.json
+
+		# Create a box waveform for audio downloaded in a pipeline
+		curl -sL https://example.com/audio.flac | waveman box -f - > audio.svg
 	`)
 )
@@ -17,42 +17,91 @@ limitations under the License.
 package cmd
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/zoomoid/waveman2/cmd/options"
 	"github.com/zoomoid/waveman2/pkg/painter"
+	"github.com/zoomoid/waveman2/pkg/peaks"
+	"github.com/zoomoid/waveman2/pkg/svg"
 )
 
 type filenameOptions struct {
-	filenames []string
-	recursive bool
-	output    string
+	filenames    []string
+	recursive    bool
+	output       string
+	outputFormat string
+	bits         int
 }
 
 type sharedPainterOptions struct {
-	height float64
-	width  float64
+	height      float64
+	width       float64
+	progress    string
+	playedColor string
+
+	animation         string
+	animationDuration time.Duration
+	animationEasing   string
+	animationStagger  time.Duration
+
+	pngWidth  int
+	pngHeight int
+	dpi       float64
 }
 
 func addDimensionFlags(flags *pflag.FlagSet, data *sharedPainterOptions) {
 	flags.Float64VarP(&data.width, options.Width, options.WidthShort, painter.DefaultWidth, options.WidthDescription)
 	flags.Float64VarP(&data.height, options.Height, options.HeightShort, painter.DefaultHeight, options.HeightDescription)
+	flags.StringVar(&data.progress, options.Progress, string(svg.ProgressNone), options.ProgressDescription)
+	flags.StringVar(&data.playedColor, options.PlayedColor, svg.DefaultPlayedColor, options.PlayedColorDescription)
+	flags.StringVar(&data.animation, options.Animation, string(svg.AnimationNone), options.AnimationDescription)
+	flags.DurationVar(&data.animationDuration, options.AnimationDuration, svg.DefaultAnimationDuration, options.AnimationDurationDescription)
+	flags.StringVar(&data.animationEasing, options.AnimationEasing, string(svg.DefaultEasing), options.AnimationEasingDescription)
+	flags.DurationVar(&data.animationStagger, options.AnimationStagger, svg.DefaultStagger, options.AnimationStaggerDescription)
+	flags.IntVar(&data.pngWidth, options.PNGWidth, 0, options.PNGWidthDescription)
+	flags.IntVar(&data.pngHeight, options.PNGHeight, 0, options.PNGHeightDescription)
+	flags.Float64Var(&data.dpi, options.DPI, svg.DefaultDPI, options.DPIDescription)
 }
 
 func addIOFlags(flags *pflag.FlagSet, data *filenameOptions) {
 	flags.StringSliceVarP(&data.filenames, options.Filename, options.FilenameShort, nil, options.FilenameDescription)
 	flags.BoolVarP(&data.recursive, options.Recursive, options.RecursiveShort, false, options.RecursiveDescription)
 	flags.StringVarP(&data.output, options.Output, options.OutputShort, "", options.OutputDescription)
+	flags.StringVar(&data.outputFormat, options.OutputFormat, string(options.FileFormatSVG), options.OutputFormatDescription)
+	flags.IntVar(&data.bits, options.Bits, peaks.DefaultBits, options.BitsDescription)
 }
 
 func addDimensionFlagsCompletion(cmd *cobra.Command) {
 	cmd.RegisterFlagCompletionFunc(options.Width, cobra.NoFileCompletions)
 	cmd.RegisterFlagCompletionFunc(options.Height, cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc(options.Progress, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return svg.ProgressModes, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.RegisterFlagCompletionFunc(options.PlayedColor, cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc(options.Animation, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return svg.AnimationModes, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.RegisterFlagCompletionFunc(options.AnimationDuration, cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc(options.AnimationEasing, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return svg.Easings, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.RegisterFlagCompletionFunc(options.AnimationStagger, cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc(options.PNGWidth, cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc(options.PNGHeight, cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc(options.DPI, cobra.NoFileCompletions)
 }
 
 func addIOFlagsCompletion(cmd *cobra.Command) {
 	cmd.RegisterFlagCompletionFunc(options.Filename, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return []string{"mp3"}, cobra.ShellCompDirectiveFilterFileExt
+		return []string{"mp3", "wav", "flac", "ogg", "opus"}, cobra.ShellCompDirectiveFilterFileExt
 	})
 	cmd.RegisterFlagCompletionFunc(options.Output, cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc(options.OutputFormat, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return options.SupportedFileFormats, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.RegisterFlagCompletionFunc(options.Bits, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"8", "16"}, cobra.ShellCompDirectiveNoFileComp
+	})
 }
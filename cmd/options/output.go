@@ -23,6 +23,18 @@ const (
 	OutputTypeEmpty OutputType = ""
 )
 
+// FileFormat is the file format waveforms are written in
+type FileFormat string
+
+const (
+	FileFormatSVG   FileFormat = "svg"
+	FileFormatPNG   FileFormat = "png"
+	FileFormatJSON  FileFormat = "json"
+	FileFormatDat   FileFormat = "dat"
+	FileFormatEmpty FileFormat = ""
+)
+
 var (
-	SupportedOutputs = []OutputType{OutputTypeFile}
+	SupportedOutputs     = []OutputType{OutputTypeFile}
+	SupportedFileFormats = []string{string(FileFormatSVG), string(FileFormatPNG), string(FileFormatJSON), string(FileFormatDat)}
 )
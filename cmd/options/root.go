@@ -17,22 +17,44 @@ limitations under the License.
 package options
 
 const (
-	Filename       string = "file"
-	FilenameShort  string = "f"
-	Output         string = "output"
-	OutputShort    string = "o"
-	Recursive      string = "recursive"
-	RecursiveShort string = "r"
-	Width          string = "width"
-	WidthShort     string = "w"
-	Height         string = "height"
-	HeightShort    string = "y"
+	Filename          string = "file"
+	FilenameShort     string = "f"
+	Output            string = "output"
+	OutputShort       string = "o"
+	Recursive         string = "recursive"
+	RecursiveShort    string = "r"
+	Width             string = "width"
+	WidthShort        string = "w"
+	Height            string = "height"
+	HeightShort       string = "y"
+	Progress          string = "progress"
+	PlayedColor       string = "played-color"
+	Animation         string = "animation"
+	AnimationDuration string = "animation-duration"
+	AnimationEasing   string = "animation-easing"
+	AnimationStagger  string = "animation-stagger"
+	OutputFormat      string = "output-format"
+	PNGWidth          string = "png-width"
+	PNGHeight         string = "png-height"
+	DPI               string = "dpi"
+	Bits              string = "bits"
 )
 
 const (
-	FilenameDescription  string = "Determines the file to be sampled, can be relative to the current working directory"
-	OutputDescription    string = "Writes the output to a given file. If not specified, writes output to stdout"
-	RecursiveDescription string = "Searches for all mp3 files in the directory below the specified file"
-	HeightDescription    string = "Height of the shape"
-	WidthDescription     string = "Width of each element"
+	FilenameDescription          string = "Determines the file to be sampled, can be relative to the current working directory. Use - to read from stdin"
+	OutputDescription            string = "Writes the output to a given file. If not specified, writes output to stdout"
+	RecursiveDescription         string = "Searches for all supported audio files (mp3, wav, flac, ogg, opus) in the directory below the specified file"
+	HeightDescription            string = "Height of the shape"
+	WidthDescription             string = "Width of each element"
+	ProgressDescription          string = "Adds a played layer on top of the waveform, clipped to the playback progress. Chose one of 'none', 'css', driven by the CSS custom property --progress in [0,1], or 'animate', animated over the duration of the audio"
+	PlayedColorDescription       string = "Color of the played layer added with --progress"
+	AnimationDescription         string = "Animates the shapes of the box, line, wave, and sweep painters. Chose one of 'none', 'grow', which grows shapes from flat to their final shape once, or 'pulse', which repeatedly flattens and restores them"
+	AnimationDurationDescription string = "Duration of the animation of each shape, e.g., 800ms"
+	AnimationEasingDescription   string = "Timing function of the animation of each shape, chose one of 'linear', 'ease', 'ease-in', 'ease-out', or 'ease-in-out'"
+	AnimationStaggerDescription  string = "Delay between the animations of consecutive shapes, e.g., 20ms. Use 0 to animate all shapes at once"
+	OutputFormatDescription      string = "Format of the output, chose one of 'svg', 'png', or the peaks formats of audiowaveform, 'json' or 'dat'. Not to be confused with --format, which sets the format of the input"
+	PNGWidthDescription          string = "Width of PNG images in pixels. Follows from the aspect ratio of the waveform if 0"
+	PNGHeightDescription         string = "Height of PNG images in pixels. Follows from the aspect ratio of the waveform if 0"
+	BitsDescription              string = "Resolution of the data points of --output-format json and dat, either 8 or 16 bits"
+	DPIDescription               string = "Resolution of PNG images without --png-width and --png-height, such that 96 DPI renders one unit of the SVG as one pixel"
 )
@@ -20,29 +20,56 @@ const (
 	DownsamplingMode   string = "downsampling-mode"
 	DownsamplingFactor string = "downsampling-factor"
 	Aggregator         string = "aggregator"
+	Layers             string = "layers"
+	Channels           string = "channels"
 	Chunks             string = "chunks"
 	ChunksShort        string = "n"
+	ChunkDuration      string = "chunk-duration"
+	Scale              string = "scale"
+	DBFloor            string = "db-floor"
+	Gamma              string = "gamma"
 
-	Normalize string = "normalize"
+	Normalize       string = "normalize"
+	NormalizeGlobal string = "normalize-global"
 
 	ClampLow  string = "clamp-low"
 	ClampHigh string = "clamp-high"
 
 	WindowP         string = "window-p"
 	WindowAlgorithm string = "window"
+
+	Format string = "format"
+
+	Streaming string = "streaming"
+
+	Bands string = "bands"
 )
 
 const (
 	DownsamplingModeDescription   string = "Determines the downsampling mode, either by sampling samples from the start, the center, or the end of a chunk"
 	DownsamplingFactorDescription string = "Determines the ratio of samples being used for downsampling compared to the full chunk's length. Given in powers of two up two 128"
-	AggregatorDescription         string = "Determines the type of aggregator function to use. Chose one of 'max', 'avg', 'rounded-avg', 'mean-square', or 'root-mean-square'"
+	AggregatorDescription         string = "Determines the type of aggregator function to use. Chose one of 'max', 'avg', 'rounded-avg', 'mean-square', 'root-mean-square', 'peak', 'min-max', which yields signed upper and lower envelopes for the line and sweep painters, or 'lufs-momentary' and 'lufs-short-term' for K-weighted loudness"
+	LayersDescription             string = "Aggregators computed in the same pass as --aggregator and drawn as stacked layers from back to front by painters supporting layers, e.g., 'peak,rms'"
+	ChannelsDescription           string = "Determines the signal derived from the stereo channels. Chose one of 'mono', 'left', 'right', 'mid', 'side', or 'split-stereo', which draws the left channel in the upper and the right channel in the lower half"
+	ScaleDescription              string = "Maps the amplitude of blocks before normalization and windowing. Chose one of 'linear', 'db', which maps levels between --db-floor and 0 dBFS to [0,1], or 'power', which raises amplitudes to --gamma"
+	DBFloorDescription            string = "Lowest level in dBFS shown with --scale db. Quieter blocks are flat"
+	GammaDescription              string = "Exponent of the power curve with --scale power. Values below 1 lift quiet blocks, values above 1 attenuate them"
 	ChunksDescription             string = "Chunks are the number of samples in the output of a transformation. For the Box painter, this also means the number of blocks, and for the Line painter, the number of root points of the line"
 
-	NormalizeDescription string = "Whether or not to normalize samples to [0,1]. When running in batch mode, this loses overall levels information, as each track is normalized individually"
+	ChunkDurationDescription string = "Duration of audio aggregated to a single chunk, e.g., 500ms. Derives the number of chunks from the length of the audio and takes precedence over --chunks"
+
+	NormalizeDescription       string = "Whether or not to normalize samples to [0,1]. When running in batch mode, this loses overall levels information, as each track is normalized individually. Use --normalize-global to retain it"
+	NormalizeGlobalDescription string = "Normalizes all files jointly, scaling the range of the entire batch like --normalize scales a single file, such that files retain their relative levels. Reads each file twice"
 
 	ClampLowDescription  string = "Lower clipping of samples"
 	ClampHighDescription string = "Upper clipping of samples"
 
 	WindowPDescription         string = "Window algorithm parameter. For most algorithms, this determines the steepness of the slope of the window"
 	WindowAlgorithmDescription string = "Window algorithm. Defaults to rectangular, which is equivalent to no windowing. Can be used with other windowing algorithms to filter high sample values at the start and end of tracks."
+
+	FormatDescription string = "Audio format of the input files, one of 'mp3', 'wav', 'flac', or 'ogg'. By default, the format is detected from the files' contents, falling back to their extension"
+
+	BandsDescription string = "Analyzes the frequency content of each chunk and splits it into low, mid, and high bands, which the box painter uses to color boxes, see --band-colors"
+
+	StreamingDescription string = "Aggregates samples in a single pass without determining the length of the audio up front. Avoids buffering stdin in memory, but ignores --downsampling-factor and --downsampling-mode"
 )
@@ -30,6 +30,9 @@ func Execute(version string) {
 		Plugin(corev1.Line).
 		Plugin(corev1.Sweep).
 		Plugin(corev1.Wave).
+		Plugin(corev1.Spectrogram).
+		Plugin(corev1.Radial).
+		Plugin(corev1.Dots).
 		Complete()
 
 	if err := rootCmd.Execute(); err != nil {
@@ -17,12 +17,18 @@ limitations under the License.
 package cmd
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/zoomoid/waveman2/cmd/options"
 	"github.com/zoomoid/waveman2/cmd/validation"
+	"github.com/zoomoid/waveman2/pkg/painter"
+	"github.com/zoomoid/waveman2/pkg/peaks"
+	"github.com/zoomoid/waveman2/pkg/plugin"
 	"github.com/zoomoid/waveman2/pkg/transform"
 	"github.com/zoomoid/waveman2/pkg/utils"
+	"github.com/zoomoid/waveman2/pkg/visitor"
 )
 
 // transformerData captures all properties defineable by flags
@@ -31,14 +37,27 @@ type transformerData struct {
 	downsamplingMode   string
 	downsamplingFactor int
 	aggregator         string
+	layers             []string
+	channels           string
 	chunks             int
+	chunkDuration      time.Duration
+	scale              string
+	dbFloor            float64
+	gamma              float64
 	normalize          bool
+	normalizeGlobal    bool
 
 	clamp *transform.Clamping
 
 	windowAlgorithm string
 
 	window *transform.Window
+
+	format string
+
+	streaming bool
+
+	bands bool
 }
 
 func newTransformerData() *transformerData {
@@ -46,6 +65,10 @@ func newTransformerData() *transformerData {
 		downsamplingMode:   string(transform.DefaultDownsamplingMode),
 		downsamplingFactor: int(transform.DefaultPrecision),
 		aggregator:         string(transform.DefaultAggregator),
+		channels:           string(transform.DefaultChannelMode),
+		scale:              string(transform.DefaultScale),
+		dbFloor:            transform.DefaultDBFloor,
+		gamma:              transform.DefaultGamma,
 		chunks:             transform.DefaultChunks,
 		normalize:          false,
 		clamp:              transform.DefaultClamping,
@@ -55,16 +78,28 @@ func newTransformerData() *transformerData {
 
 func addTranformerFlags(flags *pflag.FlagSet, data *transformerData) {
 	flags.BoolVar(&data.normalize, options.Normalize, false, options.NormalizeDescription)
+	flags.BoolVar(&data.normalizeGlobal, options.NormalizeGlobal, false, options.NormalizeGlobalDescription)
 	flags.StringVar(&data.downsamplingMode, options.DownsamplingMode, "", options.DownsamplingModeDescription)
 	flags.IntVar(&data.downsamplingFactor, options.DownsamplingFactor, 1, options.DownsamplingFactorDescription)
 	flags.StringVar(&data.aggregator, options.Aggregator, string(transform.DefaultAggregator), options.AggregatorDescription)
+	flags.StringSliceVar(&data.layers, options.Layers, nil, options.LayersDescription)
+	flags.StringVar(&data.channels, options.Channels, string(transform.DefaultChannelMode), options.ChannelsDescription)
 	flags.IntVarP(&data.chunks, options.Chunks, options.ChunksShort, transform.DefaultChunks, options.ChunksDescription)
+	flags.DurationVar(&data.chunkDuration, options.ChunkDuration, 0, options.ChunkDurationDescription)
+
+	flags.StringVar(&data.scale, options.Scale, string(transform.DefaultScale), options.ScaleDescription)
+	flags.Float64Var(&data.dbFloor, options.DBFloor, transform.DefaultDBFloor, options.DBFloorDescription)
+	flags.Float64Var(&data.gamma, options.Gamma, transform.DefaultGamma, options.GammaDescription)
 
 	flags.Float64Var(&data.clamp.Max, options.ClampHigh, transform.DefaultClamping.Max, options.ClampHighDescription)
 	flags.Float64Var(&data.clamp.Min, options.ClampLow, transform.DefaultClamping.Min, options.ClampLowDescription)
 
 	flags.StringVar(&data.windowAlgorithm, options.WindowAlgorithm, transform.DefaultWindowAlgorithm.String(), options.WindowAlgorithmDescription)
 	flags.Float64Var(&data.window.P, options.WindowP, transform.DefaultWindowParameter, options.WindowPDescription)
+
+	flags.StringVar(&data.format, options.Format, "", options.FormatDescription)
+	flags.BoolVar(&data.streaming, options.Streaming, false, options.StreamingDescription)
+	flags.BoolVar(&data.bands, options.Bands, false, options.BandsDescription)
 }
 
 func addTransformerFlagCompletion(cmd *cobra.Command) {
@@ -77,10 +112,25 @@ func addTransformerFlagCompletion(cmd *cobra.Command) {
 	cmd.RegisterFlagCompletionFunc(options.Aggregator, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return transform.Aggregators, cobra.ShellCompDirectiveNoFileComp
 	})
+	cmd.RegisterFlagCompletionFunc(options.Layers, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return transform.Aggregators, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.RegisterFlagCompletionFunc(options.Channels, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return transform.ChannelModes, cobra.ShellCompDirectiveNoFileComp
+	})
 	cmd.RegisterFlagCompletionFunc(options.WindowAlgorithm, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return transform.WindowAlgorithms, cobra.ShellCompDirectiveNoFileComp
 	})
+	cmd.RegisterFlagCompletionFunc(options.Format, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return transform.Formats(), cobra.ShellCompDirectiveNoFileComp
+	})
 	cmd.RegisterFlagCompletionFunc(options.Chunks, cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc(options.ChunkDuration, cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc(options.Scale, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return transform.Scales, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.RegisterFlagCompletionFunc(options.DBFloor, cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc(options.Gamma, cobra.NoFileCompletions)
 }
 
 func (t *transformerData) validateTransformerOptions() utils.ErrorList {
@@ -94,26 +144,119 @@ func (t *transformerData) validateTransformerOptions() utils.ErrorList {
 	if err := validation.ValidateChunks(t.chunks); err != nil {
 		errList = append(errList, err)
 	}
+	if err := validation.ValidateChunkDuration(t.chunkDuration); err != nil {
+		errList = append(errList, err)
+	}
 	if err := validation.ValidateAggregator(t.aggregator); err != nil {
 		errList = append(errList, err)
 	}
+	if err := validation.ValidateLayers(t.layers); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validation.ValidateScale(t.scale); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validation.ValidateDBFloor(t.dbFloor); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validation.ValidateGamma(t.gamma); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validation.ValidateChannelMode(t.channels); err != nil {
+		errList = append(errList, err)
+	}
 	if err := validation.ValidateWindowAlgorithm(t.windowAlgorithm); err != nil {
 		errList = append(errList, err)
 	}
+	if err := validation.ValidateFormat(t.format); err != nil {
+		errList = append(errList, err)
+	}
 	return utils.NewErrorList(errList)
 }
 
 func (t *transformerData) toOptions() *transform.ReaderOptions {
 	t.window.Algorithm = transform.WindowAlgorithmFromString(t.windowAlgorithm)
 
+	layers := make([]transform.Aggregator, 0, len(t.layers))
+	for _, layer := range t.layers {
+		layers = append(layers, transform.Aggregator(layer))
+	}
+
 	return &transform.ReaderOptions{
-		Chunks:       t.chunks,
-		Aggregator:   transform.Aggregator(t.aggregator),
-		Precision:    transform.Precision(t.downsamplingFactor),
-		Downsampling: transform.DownsamplingMode(t.downsamplingMode),
-		Normalize:    t.normalize,
+		Chunks:        t.chunks,
+		ChunkDuration: t.chunkDuration,
+		Aggregator:    transform.Aggregator(t.aggregator),
+		Layers:        layers,
+		Scale:         transform.Scale(t.scale),
+		DBFloor:       t.dbFloor,
+		Gamma:         t.gamma,
+		Channels:      transform.ChannelMode(t.channels),
+		Precision:     transform.Precision(t.downsamplingFactor),
+		Downsampling:  transform.DownsamplingMode(t.downsamplingMode),
+		Normalize:     t.normalize,
+		Format:        transform.Format(t.format),
+		Streaming:     t.streaming,
+		Bands:         t.bands,
 
 		Window:   t.window,
 		Clamping: t.clamp,
 	}
 }
+
+// newTransformer transforms a file with the given options, using the format detected from the
+// file unless --format is set. Plugins implementing plugin.TransformerConfigurer may modify the
+// options beforehand.
+func newTransformer(p plugin.Plugin, transformerOptions *transform.ReaderOptions, f *visitor.File) (*transform.ReaderContext, error) {
+	if c, ok := p.(plugin.TransformerConfigurer); ok {
+		c.ConfigureTransformer(transformerOptions)
+	}
+	if transformerOptions.Format == transform.FormatEmpty {
+		// --format takes precedence over the format detected from the file
+		transformerOptions.Format = transform.Format(f.Format())
+	}
+	return transform.New(transformerOptions, f.Reader())
+}
+
+// toLayers orders the layers of a transformer as given by --layers for painters. Returns nil
+// when no layers are requested.
+func toLayers(transformer *transform.ReaderContext, layers []transform.Aggregator) []painter.Layer {
+	if len(layers) == 0 {
+		return nil
+	}
+	left := transformer.Layers()
+	right := transformer.RightLayers()
+	out := make([]painter.Layer, 0, len(layers))
+	for _, layer := range layers {
+		l := painter.Layer{
+			Name: string(layer),
+			Data: left[layer],
+		}
+		if right != nil {
+			l.LowerData = right[layer]
+		}
+		out = append(out, l)
+	}
+	return out
+}
+
+// printPeaks writes the blocks of a transformer to a file in one of audiowaveform's formats.
+// With --channels split-stereo, the left and right channel are written as separate channels.
+func printPeaks(f *visitor.File, transformer *transform.ReaderContext, format options.FileFormat, bits int) error {
+	channels := []peaks.Channel{{Max: transformer.Blocks(), Min: transformer.LowerEnvelope()}}
+	if right := transformer.RightBlocks(); right != nil {
+		channels = append(channels, peaks.Channel{Max: right, Min: transformer.RightLowerEnvelope()})
+	}
+	p, err := peaks.New(transformer.Metadata(), bits, channels...)
+	if err != nil {
+		return err
+	}
+	encode := p.JSON
+	if format == options.FileFormatDat {
+		encode = p.Dat
+	}
+	out, err := encode()
+	if err != nil {
+		return err
+	}
+	return f.Print(out)
+}
@@ -19,9 +19,11 @@ package validation
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/spf13/pflag"
 	"github.com/zoomoid/waveman2/cmd/options"
+	"github.com/zoomoid/waveman2/pkg/svg"
 )
 
 func ValidatePainterModes(flags *pflag.FlagSet, modes []string) error {
@@ -66,6 +68,47 @@ func ValidateWidth(width float64) error {
 	return errors.New("--width must be non-negative")
 }
 
+func ValidateProgress(progress string) error {
+	p := svg.ProgressMode(progress)
+	switch p {
+	case svg.ProgressNone, svg.ProgressCSS, svg.ProgressAnimate, svg.ProgressEmpty:
+		return nil
+	}
+	return fmt.Errorf("--progress %s is not supported", progress)
+}
+
+func ValidateAnimation(animation string) error {
+	a := svg.AnimationMode(animation)
+	switch a {
+	case svg.AnimationNone, svg.AnimationGrow, svg.AnimationPulse, svg.AnimationEmpty:
+		return nil
+	}
+	return fmt.Errorf("--animation %s is not supported", animation)
+}
+
+func ValidateAnimationDuration(duration time.Duration) error {
+	if duration <= 0 {
+		return errors.New("--animation-duration must be strictly positive")
+	}
+	return nil
+}
+
+func ValidateEasing(easing string) error {
+	e := svg.Easing(easing)
+	switch e {
+	case svg.EasingLinear, svg.EasingEase, svg.EasingEaseIn, svg.EasingEaseOut, svg.EasingEaseInOut, svg.EasingEmpty:
+		return nil
+	}
+	return fmt.Errorf("--animation-easing %s is not supported", easing)
+}
+
+func ValidateAnimationStagger(stagger time.Duration) error {
+	if stagger < 0 {
+		return errors.New("--animation-stagger must be non-negative")
+	}
+	return nil
+}
+
 func ValidateOutput(output string) error {
 	o := options.OutputType(output)
 	switch o {
@@ -75,6 +118,36 @@ func ValidateOutput(output string) error {
 	return fmt.Errorf("--output does not support type %s, only supported types are %v", output, options.SupportedOutputs)
 }
 
+func ValidateOutputFormat(format string) error {
+	f := options.FileFormat(format)
+	switch f {
+	case options.FileFormatSVG, options.FileFormatPNG, options.FileFormatJSON, options.FileFormatDat, options.FileFormatEmpty:
+		return nil
+	}
+	return fmt.Errorf("--output-format %s is not supported, only supported formats are %v", format, options.SupportedFileFormats)
+}
+
+func ValidatePNGSize(width int, height int) error {
+	if width < 0 || height < 0 {
+		return errors.New("--png-width and --png-height must be non-negative")
+	}
+	return nil
+}
+
+func ValidateBits(bits int) error {
+	if bits != 8 && bits != 16 {
+		return fmt.Errorf("--bits %d is not supported, chose either 8 or 16", bits)
+	}
+	return nil
+}
+
+func ValidateDPI(dpi float64) error {
+	if dpi <= 0 {
+		return errors.New("--dpi must be strictly positive")
+	}
+	return nil
+}
+
 // func ValidateFilenames(filenames []string, output string) error {
 // 	if options.OutputType(output) == options.OutputTypeEmpty && len(filenames) > 1 {
 // 		return fmt.Errorf("cannot use multiple files with stdout target, use --output file")
@@ -18,7 +18,9 @@ package validation
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/zoomoid/waveman2/pkg/decoder/registry"
 	"github.com/zoomoid/waveman2/pkg/transform"
 )
 
@@ -58,20 +60,84 @@ func ValidateChunks(chunks int) error {
 	return fmt.Errorf("downsampling factor must be strictly positve")
 }
 
+func ValidateChunkDuration(duration time.Duration) error {
+	if duration >= 0 {
+		return nil
+	}
+	return fmt.Errorf("chunk duration must be non-negative")
+}
+
 func ValidateAggregator(aggregator string) error {
 	a := transform.Aggregator(aggregator)
 	switch a {
 	case transform.AggregatorAverage,
 		transform.AggregatorMax,
+		transform.AggregatorPeak,
+		transform.AggregatorMinMax,
 		transform.AggregatorMeanSquare,
 		transform.AggregatorRootMeanSquare,
 		transform.AggregatorRoundedAverage,
+		transform.AggregatorLoudnessMomentary,
+		transform.AggregatorLoudnessShortTerm,
 		transform.AggregatorEmpty:
 		return nil
 	}
 	return fmt.Errorf("aggregator %s is not supported", aggregator)
 }
 
+func ValidateScale(scale string) error {
+	s := transform.Scale(scale)
+	switch s {
+	case transform.ScaleLinear,
+		transform.ScaleDecibels,
+		transform.ScalePower,
+		transform.ScaleEmpty:
+		return nil
+	}
+	return fmt.Errorf("scale %s is not supported", scale)
+}
+
+func ValidateDBFloor(floor float64) error {
+	if floor < 0 {
+		return nil
+	}
+	return fmt.Errorf("dB floor must be strictly negative")
+}
+
+func ValidateGamma(gamma float64) error {
+	if gamma > 0 {
+		return nil
+	}
+	return fmt.Errorf("gamma must be strictly positive")
+}
+
+func ValidateChannelMode(mode string) error {
+	m := transform.ChannelMode(mode)
+	switch m {
+	case transform.ChannelMono,
+		transform.ChannelLeft,
+		transform.ChannelRight,
+		transform.ChannelMid,
+		transform.ChannelSide,
+		transform.ChannelSplitStereo,
+		transform.ChannelEmpty:
+		return nil
+	}
+	return fmt.Errorf("channel mode %s is not supported", mode)
+}
+
+func ValidateLayers(layers []string) error {
+	for _, layer := range layers {
+		if transform.Aggregator(layer) == transform.AggregatorEmpty {
+			return fmt.Errorf("layers must not be empty")
+		}
+		if err := ValidateAggregator(layer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func ValidateWindowAlgorithm(windowAlgorithm string) error {
 	a := transform.WindowAlgorithmFromString(windowAlgorithm)
 	switch a {
@@ -80,3 +146,13 @@ func ValidateWindowAlgorithm(windowAlgorithm string) error {
 	}
 	return fmt.Errorf("window algorithm %s is not supported", windowAlgorithm)
 }
+
+func ValidateFormat(format string) error {
+	if transform.Format(format) == transform.FormatEmpty {
+		return nil
+	}
+	if _, err := registry.Lookup(format); err != nil {
+		return fmt.Errorf("format %s is not supported, supported formats are %v", format, transform.Formats())
+	}
+	return nil
+}
@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"fmt"
+	"hash/fnv"
 
 	"errors"
 
@@ -26,6 +27,7 @@ import (
 	"github.com/zoomoid/waveman2/cmd/options"
 	"github.com/zoomoid/waveman2/cmd/validation"
 	"github.com/zoomoid/waveman2/pkg/painter"
+	"github.com/zoomoid/waveman2/pkg/peaks"
 	"github.com/zoomoid/waveman2/pkg/plugin"
 	"github.com/zoomoid/waveman2/pkg/streams"
 	"github.com/zoomoid/waveman2/pkg/svg"
@@ -116,30 +118,109 @@ func (w *Waveman) Plugin(plugin plugin.Plugin) *Waveman {
 		Long: plugin.Description(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			p := plugin
-			err := w.jobs.Visit(func(f *visitor.File) error {
-				transformer, err := transform.New(w.options.transformerData.toOptions(), f.Reader())
+			var reference *transform.Levels
+			draw := func(f *visitor.File) error {
+				transformerOptions := w.options.transformerData.toOptions()
+				if reference != nil {
+					transformerOptions.Normalize = true
+					transformerOptions.Reference = reference
+				}
+				transformer, err := newTransformer(p, transformerOptions, f)
 				if err != nil {
 					return err
 				}
 				samples := transformer.Blocks()
+				switch format := options.FileFormat(w.options.outputFormat); format {
+				case options.FileFormatJSON, options.FileFormatDat:
+					// peaks only contain the transformed data, which players render themselves
+					return printPeaks(f, transformer, format, w.options.bits)
+				}
 				if p == nil {
 					return fmt.Errorf("painter is nil")
 				}
 				elements := p.Draw(&painter.PainterOptions{
-					Data:   samples,
-					Height: w.options.height,
-					Width:  w.options.width,
+					Data:          samples,
+					LowerData:     transformer.RightBlocks(),
+					LowerEnvelope: transformer.LowerEnvelope(),
+					Layers:        toLayers(transformer, transformerOptions.Layers),
+					Bands:         transformer.Bands(),
+					Spectrogram:   transformer.Spectrogram(),
+					Height:        w.options.height,
+					Width:         w.options.width,
+					Metadata:      transformer.Metadata(),
+					ID:            documentID(f),
 				})
-				out, err := svg.Template(elements, true, p.Painter().Viewbox())
+				if mode := svg.AnimationMode(w.options.animation); mode != svg.AnimationNone && mode != svg.AnimationEmpty {
+					axisPainter, ok := p.Painter().(painter.AxisPainter)
+					if !ok {
+						return fmt.Errorf("painter %s does not support --animation", p.Name())
+					}
+					elements = svg.Animate(elements, &svg.AnimationOptions{
+						Mode:     mode,
+						Duration: w.options.animationDuration,
+						Easing:   svg.Easing(w.options.animationEasing),
+						Stagger:  w.options.animationStagger,
+						Axis:     axisPainter.Axis(),
+					})
+				}
+				defs := painter.Defs(p.Painter())
+				progressDefs, elements, err := svg.Progress(elements, &svg.ProgressOptions{
+					Mode:        svg.ProgressMode(w.options.progress),
+					PlayedColor: w.options.playedColor,
+					Duration:    transformer.Metadata().Duration,
+					Width:       p.Painter().Width(),
+					Height:      p.Painter().Height(),
+					ID:          documentID(f),
+				})
+				if err != nil {
+					return err
+				}
+				out, err := svg.Template(elements, true, p.Painter().Viewbox(), append(defs, progressDefs...)...)
 				if err != nil {
 					return err
 				}
+				if options.FileFormat(w.options.outputFormat) == options.FileFormatPNG {
+					out, err = svg.EncodePNG(out, &svg.RasterOptions{
+						Width:  w.options.pngWidth,
+						Height: w.options.pngHeight,
+						DPI:    w.options.dpi,
+					})
+					if err != nil {
+						return err
+					}
+				}
 				f.Print(out)
 
 				return nil
-			})
+			}
 
-			return err
+			// errors of individual files are collected to continue with the remaining ones,
+			// and reported once all files are visited
+			defer func() {
+				for _, err := range w.jobs.Errors() {
+					log.Error().Err(err).Msg("failed to draw waveform")
+				}
+			}()
+			if !w.options.normalizeGlobal {
+				return w.jobs.Visit(draw)
+			}
+			// transform all files without normalization first to find the levels of the entire
+			// batch, and normalize all files against them when drawing them, which is the same
+			// scaling as --normalize applied to all files at once
+			return w.jobs.VisitBatch(func(f *visitor.File) error {
+				transformerOptions := w.options.transformerData.toOptions()
+				transformerOptions.Normalize = false
+				transformer, err := newTransformer(nil, transformerOptions, f)
+				if err != nil {
+					return err
+				}
+				levels := transformer.Levels()
+				if reference != nil {
+					levels = reference.Merge(levels)
+				}
+				reference = &levels
+				return nil
+			}, draw)
 		},
 	}
 
@@ -157,6 +238,14 @@ func (w *Waveman) Plugin(plugin plugin.Plugin) *Waveman {
 	return w
 }
 
+// documentID derives the prefix of all IDs of a file's SVG from its source, such that SVGs of
+// different files can be inlined into the same HTML page
+func documentID(f *visitor.File) string {
+	h := fnv.New32a()
+	h.Write([]byte(f.Source()))
+	return fmt.Sprintf("waveman-%08x", h.Sum32())
+}
+
 // Complete finalizes the Waveman configuration and creates a runner
 func (w *Waveman) Complete() *cobra.Command {
 	w.cmd.PersistentPreRunE = func(_ *cobra.Command, _ []string) error {
@@ -183,9 +272,19 @@ func (w *Waveman) Complete() *cobra.Command {
 			log.Fatal().Msg(el.Error())
 		}
 
+		extension := visitor.DefaultSVGExtension
+		switch options.FileFormat(w.options.outputFormat) {
+		case options.FileFormatPNG:
+			extension = visitor.DefaultPNGExtension
+		case options.FileFormatJSON:
+			extension = visitor.DefaultJSONExtension
+		case options.FileFormatDat:
+			extension = visitor.DefaultDatExtension
+		}
 		w.jobs = visitors.
 			ContinueOnError().
-			UseStdout(useStdout)
+			UseStdout(useStdout).
+			OutputExtension(extension)
 
 		return err
 	}
@@ -210,15 +309,26 @@ func NewWavemanOptions() *WavemanOptions {
 
 func newSharedPainterData() *sharedPainterOptions {
 	return &sharedPainterOptions{
-		height: painter.DefaultHeight,
-		width:  painter.DefaultWidth,
+		height:      painter.DefaultHeight,
+		width:       painter.DefaultWidth,
+		progress:    string(svg.ProgressNone),
+		playedColor: svg.DefaultPlayedColor,
+
+		animation:         string(svg.AnimationNone),
+		animationDuration: svg.DefaultAnimationDuration,
+		animationEasing:   string(svg.DefaultEasing),
+		animationStagger:  svg.DefaultStagger,
+
+		dpi: svg.DefaultDPI,
 	}
 }
 
 func newFilenameData() *filenameOptions {
 	return &filenameOptions{
-		filenames: []string{},
-		recursive: false,
+		filenames:    []string{},
+		recursive:    false,
+		outputFormat: string(options.FileFormatSVG),
+		bits:         peaks.DefaultBits,
 	}
 }
 
@@ -235,9 +345,36 @@ func (o *WavemanOptions) Validate() error {
 	if err := validation.ValidateWidth(o.width); err != nil {
 		return err
 	}
+	if err := validation.ValidateProgress(o.progress); err != nil {
+		return err
+	}
+	if err := validation.ValidateAnimation(o.animation); err != nil {
+		return err
+	}
+	if err := validation.ValidateAnimationDuration(o.animationDuration); err != nil {
+		return err
+	}
+	if err := validation.ValidateEasing(o.animationEasing); err != nil {
+		return err
+	}
+	if err := validation.ValidateAnimationStagger(o.animationStagger); err != nil {
+		return err
+	}
 	if err := validation.ValidateOutput(o.output); err != nil {
 		return err
 	}
+	if err := validation.ValidateOutputFormat(o.outputFormat); err != nil {
+		return err
+	}
+	if err := validation.ValidatePNGSize(o.pngWidth, o.pngHeight); err != nil {
+		return err
+	}
+	if err := validation.ValidateDPI(o.dpi); err != nil {
+		return err
+	}
+	if err := validation.ValidateBits(o.bits); err != nil {
+		return err
+	}
 	// if err := validation.ValidateFilenames(o.filenames); err != nil {
 	// 	return err
 	// }
@@ -31,6 +31,9 @@ func main() {
 		Plugin(corev1.Line).
 		Plugin(corev1.Wave).
 		Plugin(corev1.Sweep).
+		Plugin(corev1.Spectrogram).
+		Plugin(corev1.Radial).
+		Plugin(corev1.Dots).
 		Complete()
 	err := doc.GenMarkdownTree(waveman, "./")
 	if err != nil {
@@ -18,3 +18,37 @@ type Decoder interface {
 	io.Seeker
 	io.Closer
 }
+
+// SampleRater is implemented by decoders that know the sample rate of their source
+type SampleRater interface {
+	// SampleRate returns the sample rate of the source in Hz
+	SampleRate() int
+}
+
+// ChannelCounter is implemented by decoders that know the number of channels of their source
+type ChannelCounter interface {
+	// Channels returns the number of channels of the source before folding down to stereo
+	Channels() int
+}
+
+// Fold folds a single frame of samples with any number of channels down to stereo.
+//
+// Mono frames are duplicated onto both channels. Frames with more than two channels are folded
+// by averaging all even channels into the left and all odd channels into the right channel,
+// which keeps all channels visible in the waveform while preserving the overall level.
+func Fold(channels []float64) (sample [2]float64) {
+	if len(channels) == 1 {
+		return [2]float64{channels[0], channels[0]}
+	}
+	var counts [2]int
+	for c, x := range channels {
+		sample[c%2] += x
+		counts[c%2]++
+	}
+	for c := range sample {
+		if counts[c] > 0 {
+			sample[c] /= float64(counts[c])
+		}
+	}
+	return sample
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flac
+
+import (
+	"errors"
+	"io"
+	"math"
+
+	"github.com/mewkiz/flac"
+	"github.com/mewkiz/flac/frame"
+	"github.com/zoomoid/waveman2/pkg/decoder"
+)
+
+var (
+	ErrInvalidWhence    error = errors.New("flac: invalid whence")
+	ErrNegativePosition error = errors.New("flac: negative position")
+)
+
+// Decoder implements decoder.Decoder for FLAC streams.
+//
+// Like the WAV decoder, the FLAC decoder exposes the audio as if it were 16 bit stereo,
+// i.e., each frame is decoder.FrameWidth bytes wide, such that the transformer's byte offsets
+// remain valid. Mono and multichannel streams are folded to stereo with decoder.Fold.
+type Decoder struct {
+	stream   *flac.Stream
+	seekable bool
+
+	channels      int
+	bitsPerSample int
+	sampleRate    int
+	// samples is the total number of inter-channel samples in the stream, or 0 if unknown
+	samples int64
+
+	// frame is the currently decoded FLAC frame, nil if none was decoded yet
+	frame *frame.Frame
+	// offset is the index of the next sample to be read from the current frame
+	offset int
+	// pos is the index of the next sample frame to be read from the stream
+	pos int64
+	// eof is set when seeking past the end of the stream
+	eof bool
+
+	scratch []float64
+}
+
+// NewDecoder parses the FLAC metadata blocks of f and returns a decoder positioned at the first
+// audio frame. When f is io.ReadSeeker, the decoder supports seeking using the stream's
+// SEEKTABLE if present, or a seek table built from scanning all frame headers otherwise.
+func NewDecoder(f io.Reader) (*Decoder, error) {
+	var stream *flac.Stream
+	var err error
+	rs, seekable := f.(io.ReadSeeker)
+	if seekable {
+		stream, err = flac.NewSeek(rs)
+	} else {
+		stream, err = flac.New(f)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Decoder{
+		stream:        stream,
+		seekable:      seekable,
+		channels:      int(stream.Info.NChannels),
+		bitsPerSample: int(stream.Info.BitsPerSample),
+		sampleRate:    int(stream.Info.SampleRate),
+		samples:       int64(stream.Info.NSamples),
+		scratch:       make([]float64, stream.Info.NChannels),
+	}
+	return d, nil
+}
+
+// Length returns the total size in bytes, as if the stream was decoded to 16 bit stereo.
+//
+// Length returns -1 when the total number of samples is not stored in the STREAMINFO block.
+func (d *Decoder) Length() int {
+	if d.samples == 0 {
+		return -1
+	}
+	return int(d.samples) * decoder.FrameWidth
+}
+
+// SampleRate returns the sample rate of the stream in Hz
+func (d *Decoder) SampleRate() int {
+	return d.sampleRate
+}
+
+// Channels returns the number of channels stored in the stream before folding down to stereo
+func (d *Decoder) Channels() int {
+	return d.channels
+}
+
+// Fills the samples slice with len(samples) samples.
+func (d *Decoder) Read(samples [][2]float64) (n int, err error) {
+	if d.eof {
+		return 0, nil
+	}
+	for n < len(samples) {
+		if d.frame == nil || d.offset >= int(d.frame.BlockSize) {
+			err := d.next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return n, err
+			}
+		}
+		for ; d.offset < int(d.frame.BlockSize) && n < len(samples); d.offset++ {
+			samples[n] = d.sample(d.offset)
+			n++
+		}
+	}
+	d.pos += int64(n)
+	return n, nil
+}
+
+// next parses the next frame from the stream
+func (d *Decoder) next() error {
+	f, err := d.stream.ParseNext()
+	if err != nil {
+		return err
+	}
+	d.frame = f
+	d.offset = 0
+	return nil
+}
+
+// sample folds the i-th sample of the current frame down to stereo
+func (d *Decoder) sample(i int) [2]float64 {
+	bps := d.bitsPerSample
+	if d.frame.BitsPerSample != 0 {
+		bps = int(d.frame.BitsPerSample)
+	}
+	scale := math.Exp2(float64(bps)-1) - 1
+	for c, subframe := range d.frame.Subframes {
+		d.scratch[c] = float64(subframe.Samples[i]) / scale
+	}
+	return decoder.Fold(d.scratch[:len(d.frame.Subframes)])
+}
+
+// Seek is io.Seeker's Seek.
+//
+// Offsets are interpreted as if the stream was decoded to 16 bit stereo, see Length. When the
+// underlying source is not io.Seeker, only seeking forwards is supported by decoding and
+// discarding frames.
+func (d *Decoder) Seek(offset int64, whence int) (int64, error) {
+	cur := d.pos * int64(decoder.FrameWidth)
+	var npos int64
+	switch whence {
+	case io.SeekStart:
+		npos = offset
+	case io.SeekCurrent:
+		npos = cur + offset
+	case io.SeekEnd:
+		npos = int64(d.Length()) + offset
+	default:
+		return 0, ErrInvalidWhence
+	}
+	if npos < 0 {
+		return 0, ErrNegativePosition
+	}
+	target := npos / int64(decoder.FrameWidth)
+	if target == d.pos {
+		return npos, nil
+	}
+	d.eof = false
+
+	if d.samples > 0 && target >= d.samples {
+		// flac.Stream.Seek refuses to seek past the last sample, so we clamp to the end
+		// of the stream, after which Read returns no more samples
+		d.frame = nil
+		d.offset = 0
+		d.pos = d.samples
+		d.eof = true
+		return npos, nil
+	}
+
+	if !d.seekable {
+		if target < d.pos {
+			return 0, errors.New("flac: cannot seek backwards in a non-seekable source")
+		}
+		for d.pos < target {
+			if d.frame == nil || d.offset >= int(d.frame.BlockSize) {
+				if err := d.next(); err != nil {
+					return d.pos * int64(decoder.FrameWidth), err
+				}
+			}
+			skip := int64(d.frame.BlockSize) - int64(d.offset)
+			if d.pos+skip > target {
+				skip = target - d.pos
+			}
+			d.offset += int(skip)
+			d.pos += skip
+		}
+		return npos, nil
+	}
+
+	// Stream.Seek positions the stream at the start of the frame containing the target sample
+	start, err := d.stream.Seek(uint64(target))
+	if err != nil {
+		return 0, err
+	}
+	if err := d.next(); err != nil {
+		return 0, err
+	}
+	d.offset = int(target - int64(start))
+	d.pos = target
+	return npos, nil
+}
+
+// Decode converts a single interleaved frame of little-endian signed integer samples at the
+// stream's bit depth, i.e., the layout of an equivalent WAV file, to a pair of float64
+// samples for the left and right channel, and returns the number of bytes consumed.
+func (d *Decoder) Decode(p []byte) (sample [2]float64, n int) {
+	width := (d.bitsPerSample + 7) / 8
+	scale := math.Exp2(float64(d.bitsPerSample)-1) - 1
+	for c := range d.scratch {
+		var x uint64
+		for i := width - 1; i >= 0; i-- {
+			x = x<<8 | uint64(p[c*width+i])
+		}
+		// sign-extend from the sample width
+		shift := 64 - uint(width*8)
+		d.scratch[c] = float64(int64(x<<shift)>>shift) / scale
+	}
+	return decoder.Fold(d.scratch), width * d.channels
+}
+
+// Close is a no-op, the underlying source is owned by the caller
+func (d *Decoder) Close() error {
+	return nil
+}
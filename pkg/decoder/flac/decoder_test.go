@@ -0,0 +1,138 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flac
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/mewkiz/flac"
+	"github.com/mewkiz/flac/frame"
+	"github.com/mewkiz/flac/meta"
+	"github.com/zoomoid/waveman2/pkg/decoder"
+)
+
+const (
+	testBlockSize = 256
+	testBlocks    = 8
+)
+
+// encode creates an in-memory 16 bit stereo FLAC stream of testBlocks frames, where the left
+// channel counts up from 0 and the right channel counts down from 0
+func encode(t *testing.T) []byte {
+	out := &bytes.Buffer{}
+	info := &meta.StreamInfo{
+		BlockSizeMin:  testBlockSize,
+		BlockSizeMax:  testBlockSize,
+		SampleRate:    44100,
+		NChannels:     2,
+		BitsPerSample: 16,
+		NSamples:      testBlockSize * testBlocks,
+	}
+	enc, err := flac.NewEncoder(out, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for b := 0; b < testBlocks; b++ {
+		left := make([]int32, testBlockSize)
+		right := make([]int32, testBlockSize)
+		for i := range left {
+			left[i] = int32(b*testBlockSize + i)
+			right[i] = -left[i]
+		}
+		f := &frame.Frame{
+			Header: frame.Header{
+				HasFixedBlockSize: true,
+				BlockSize:         testBlockSize,
+				SampleRate:        info.SampleRate,
+				Channels:          frame.ChannelsLR,
+				BitsPerSample:     info.BitsPerSample,
+				Num:               uint64(b),
+			},
+			Subframes: []*frame.Subframe{
+				{SubHeader: frame.SubHeader{Pred: frame.PredVerbatim}, Samples: left, NSamples: testBlockSize},
+				{SubHeader: frame.SubHeader{Pred: frame.PredVerbatim}, Samples: right, NSamples: testBlockSize},
+			},
+		}
+		if err := enc.WriteFrame(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+func TestDecoderRead(t *testing.T) {
+	d, err := NewDecoder(bytes.NewReader(encode(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Length() != testBlockSize*testBlocks*decoder.FrameWidth {
+		t.Fatalf("expected length %d, found %d", testBlockSize*testBlocks*decoder.FrameWidth, d.Length())
+	}
+	// read across frame boundaries
+	samples := make([][2]float64, testBlockSize+testBlockSize/2)
+	n, err := d.Read(samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(samples) {
+		t.Fatalf("expected %d samples, found %d", len(samples), n)
+	}
+	for i, s := range samples {
+		expected := float64(i) / 32767
+		if s[0] != expected || s[1] != -expected {
+			t.Fatalf("sample %d: expected [%g %g], found %v", i, expected, -expected, s)
+		}
+	}
+}
+
+func TestDecoderSeek(t *testing.T) {
+	raw := encode(t)
+	for name, r := range map[string]io.Reader{
+		"seekable":     bytes.NewReader(raw),
+		"non-seekable": io.MultiReader(bytes.NewReader(raw)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			d, err := NewDecoder(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			target := int64(3*testBlockSize + 17)
+			if _, err := d.Seek(target*int64(decoder.FrameWidth), io.SeekStart); err != nil {
+				t.Fatal(err)
+			}
+			samples := make([][2]float64, 1)
+			if _, err := d.Read(samples); err != nil {
+				t.Fatal(err)
+			}
+			if samples[0][0] != float64(target)/32767 {
+				t.Errorf("expected %g after seeking to sample %d, found %g", float64(target)/32767, target, samples[0][0])
+			}
+			// seeking past the end clamps to the end of the stream
+			if _, err := d.Seek(int64(d.Length()+decoder.FrameWidth), io.SeekStart); err != nil {
+				t.Fatal(err)
+			}
+			if n, _ := d.Read(samples); n != 0 {
+				t.Errorf("expected no samples after seeking past the end, found %d", n)
+			}
+		})
+	}
+}
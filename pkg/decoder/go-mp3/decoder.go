@@ -62,6 +62,19 @@ func (d *Decoder) Length() int {
 	return int(d.decoder.Length())
 }
 
+// SampleRate returns the sample rate in Hz as retrieved from the first frame.
+//
+// Wrapper for (mp3.Decoder).SampleRate
+func (d *Decoder) SampleRate() int {
+	return d.decoder.SampleRate()
+}
+
+// Channels returns the number of channels, which is always 2 as go-mp3 decodes mono
+// streams to stereo
+func (d *Decoder) Channels() int {
+	return d.channels
+}
+
 // Fills the samples slice with len(samples) samples.
 //
 // Wrapper for (mp3.Decoder).Read
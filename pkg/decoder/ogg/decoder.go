@@ -0,0 +1,377 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ogg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/zoomoid/waveman2/pkg/decoder"
+)
+
+var (
+	ErrUnsupportedCodec error = errors.New("ogg: unsupported codec, expected vorbis or opus")
+	ErrInvalidWhence    error = errors.New("ogg: invalid whence")
+	ErrNegativePosition error = errors.New("ogg: negative position")
+)
+
+// codec decodes the packets of a logical bitstream
+type codec interface {
+	// header consumes a header packet and returns true once all header packets were read
+	header(packet []byte) (done bool, err error)
+	sampleRate() int
+	channels() int
+	// preSkip returns the number of samples to be discarded at the start of the stream
+	preSkip() int64
+	// preRoll returns the number of samples to decode before a seek target, such that the
+	// codec produces valid output at the target
+	preRoll() int64
+	// decode appends the samples of an audio packet, folded down to stereo, to out
+	decode(packet []byte, out [][2]float64) ([][2]float64, error)
+	// reset discards the codec's state, e.g., after seeking
+	reset()
+}
+
+// codecs maps the magic prefix of a logical bitstream's first packet to its codec
+var codecs = map[string]func() codec{
+	"\x01vorbis": func() codec { return newVorbisCodec() },
+	"OpusHead":   func() codec { return newOpusCodec() },
+}
+
+func newCodec(packet []byte) (codec, error) {
+	for magic, c := range codecs {
+		if bytes.HasPrefix(packet, []byte(magic)) {
+			return c(), nil
+		}
+	}
+	return nil, ErrUnsupportedCodec
+}
+
+// indexEntry locates a page of the logical bitstream for seeking
+type indexEntry struct {
+	offset  int64
+	granule int64
+}
+
+// Decoder implements decoder.Decoder for Ogg files containing Vorbis or Opus streams.
+//
+// Like the other decoders for formats other than mp3, the Ogg decoder exposes the audio as if it
+// were 16 bit stereo, i.e., each frame is decoder.FrameWidth bytes wide, such that the
+// transformer's byte offsets remain valid. Mono and multichannel streams are folded to stereo
+// with decoder.Fold.
+type Decoder struct {
+	reader *packetReader
+	codec  codec
+
+	// audioStart is the offset of the first page holding audio packets
+	audioStart int64
+	// index holds all pages of the logical bitstream after the headers, nil if the source is
+	// not io.Seeker
+	index []indexEntry
+	// samples is the total number of samples in the stream, or -1 if unknown
+	samples int64
+
+	// buffer holds decoded samples not yet returned by Read
+	buffer [][2]float64
+	// bufferPos is the sample position of buffer[0]. Positions are only known after decoding
+	// the last packet of a page, which carries the page's granule position, so bufferPos is
+	// invalid until anchored is set.
+	bufferPos int64
+	anchored  bool
+	// fromStart is set while decoding from the first audio page, where positions start at
+	// the beginning of the stream
+	fromStart bool
+
+	// pos is the index of the next sample frame to be returned by Read
+	pos int64
+	eof bool
+
+	scratch []float64
+}
+
+// NewDecoder parses the Ogg pages of f up to the end of the codec's header packets and returns a
+// decoder positioned at the first sample. When f is io.ReadSeeker, NewDecoder scans all page
+// headers to determine the stream's length and to build an index for seeking.
+func NewDecoder(f io.Reader) (*Decoder, error) {
+	d := &Decoder{
+		reader:    newPacketReader(f),
+		samples:   -1,
+		fromStart: true,
+	}
+
+	p, err := d.reader.next()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrNotOgg
+		}
+		return nil, err
+	}
+	d.codec, err = newCodec(p.data)
+	if err != nil {
+		return nil, err
+	}
+	for done := false; !done; {
+		done, err = d.codec.header(p.data)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			break
+		}
+		if p, err = d.reader.next(); err != nil {
+			return nil, fmt.Errorf("ogg: incomplete headers: %w", err)
+		}
+	}
+	d.audioStart = d.reader.offset
+	d.scratch = make([]float64, d.codec.channels())
+
+	if s, ok := f.(io.ReadSeeker); ok {
+		if err := d.buildIndex(s); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// buildIndex scans all page headers following the header packets and restores the source's
+// position afterwards
+func (d *Decoder) buildIndex(s io.ReadSeeker) error {
+	if _, err := s.Seek(d.audioStart, io.SeekStart); err != nil {
+		return err
+	}
+	r := bufio.NewReaderSize(s, 1<<16)
+	offset := d.audioStart
+	d.index = []indexEntry{}
+	for {
+		p, err := readPage(r, offset, false)
+		if err != nil {
+			// stop at the end of the source or at the first corrupt page
+			break
+		}
+		offset += p.size()
+		if p.serial != d.reader.serial {
+			continue
+		}
+		d.index = append(d.index, indexEntry{offset: p.offset, granule: p.granule})
+		if p.granule >= 0 {
+			d.samples = max(p.granule-d.codec.preSkip(), 0)
+		}
+		if p.flags&flagEOS != 0 {
+			break
+		}
+	}
+	_, err := s.Seek(d.audioStart, io.SeekStart)
+	d.reader.r.Reset(s)
+	return err
+}
+
+// Length returns the total size in bytes, as if the stream was decoded to 16 bit stereo.
+//
+// Length returns -1 when the source is not io.Seeker, as the length is only stored in the
+// granule position of the stream's last page.
+func (d *Decoder) Length() int {
+	if d.samples < 0 {
+		return -1
+	}
+	return int(d.samples) * decoder.FrameWidth
+}
+
+// SampleRate returns the sample rate of the decoded audio in Hz, which is always 48kHz for Opus
+func (d *Decoder) SampleRate() int {
+	return d.codec.sampleRate()
+}
+
+// Channels returns the number of channels stored in the stream before folding down to stereo
+func (d *Decoder) Channels() int {
+	return d.codec.channels()
+}
+
+// Fills the samples slice with len(samples) samples.
+func (d *Decoder) Read(samples [][2]float64) (n int, err error) {
+	for n < len(samples) && !d.eof {
+		if d.anchored && len(d.buffer) > 0 {
+			if d.bufferPos > d.pos {
+				// decoding resumed later than expected after seeking, so skip the gap
+				d.pos = d.bufferPos
+			}
+			// drop samples before the current position, i.e., pre-skip and pre-roll
+			if skip := d.pos - d.bufferPos; skip > 0 {
+				skip = min(skip, int64(len(d.buffer)))
+				d.buffer = d.buffer[skip:]
+				d.bufferPos += skip
+			}
+			c := copy(samples[n:], d.buffer)
+			d.buffer = d.buffer[c:]
+			d.bufferPos += int64(c)
+			d.pos += int64(c)
+			n += c
+			continue
+		}
+		if err := d.fill(); err != nil {
+			if errors.Is(err, io.EOF) {
+				d.eof = true
+				break
+			}
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// fill decodes the next packet into the buffer
+func (d *Decoder) fill() error {
+	p, err := d.reader.next()
+	if errors.Is(err, io.EOF) && !d.anchored && len(d.buffer) > 0 {
+		// the stream ended without a granule position, so assume contiguous samples
+		d.anchor(d.pos+int64(len(d.buffer)), true)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	d.buffer, err = d.codec.decode(p.data, d.buffer)
+	if err != nil {
+		return err
+	}
+	if p.granule < 0 {
+		return nil
+	}
+
+	end := p.granule - d.codec.preSkip()
+	if !d.anchored {
+		d.anchor(end, p.eos)
+	}
+	if p.eos && d.bufferPos+int64(len(d.buffer)) > end {
+		// the last page trims the samples of the final packet
+		d.buffer = d.buffer[:max(end-d.bufferPos, 0)]
+	}
+	return nil
+}
+
+// anchor determines the position of the buffered samples from the position of the end of the
+// last decoded packet
+func (d *Decoder) anchor(end int64, eos bool) {
+	d.anchored = true
+	d.bufferPos = end - int64(len(d.buffer))
+	if d.fromStart {
+		// positions are known when decoding from the start of the stream. The granule position
+		// of the first page only discards leading samples, unless the stream already ends on
+		// it, in which case the trailing samples are discarded.
+		start := -d.codec.preSkip()
+		if eos || d.bufferPos > start {
+			d.bufferPos = start
+		}
+	}
+}
+
+// Seek is io.Seeker's Seek.
+//
+// Offsets are interpreted as if the stream was decoded to 16 bit stereo, see Length. Seeking
+// jumps to the page preceding the target by the codec's pre-roll and decodes up to the target.
+// When the underlying source is not io.Seeker, only seeking forwards is supported by decoding
+// and discarding packets.
+func (d *Decoder) Seek(offset int64, whence int) (int64, error) {
+	cur := d.pos * int64(decoder.FrameWidth)
+	var npos int64
+	switch whence {
+	case io.SeekStart:
+		npos = offset
+	case io.SeekCurrent:
+		npos = cur + offset
+	case io.SeekEnd:
+		npos = int64(d.Length()) + offset
+	default:
+		return 0, ErrInvalidWhence
+	}
+	if npos < 0 {
+		return 0, ErrNegativePosition
+	}
+	target := npos / int64(decoder.FrameWidth)
+	if d.samples >= 0 && target > d.samples {
+		target = d.samples
+	}
+	if target == d.pos {
+		return npos, nil
+	}
+	if d.samples >= 0 && target == d.samples {
+		// nothing is left to decode at the end of the stream
+		d.buffer = d.buffer[:0]
+		d.pos = target
+		d.eof = true
+		return npos, nil
+	}
+
+	// short forward seeks decode through instead of jumping, Read drops the samples up to
+	// the new position
+	if target > d.pos && (d.index == nil || target-d.pos <= d.codec.preRoll()) {
+		d.pos = target
+		return npos, nil
+	}
+	if d.index == nil {
+		return 0, errors.New("ogg: cannot seek backwards in a non-seekable source")
+	}
+
+	// find the last page ending before the target minus the pre-roll and start decoding on the
+	// page following it. Positions are derived from the first page with a granule position
+	// decoded after seeking, which must not be the last one, as its granule position may trim
+	// the stream's final samples.
+	start := target - d.codec.preRoll()
+	var granules []int
+	for i, e := range d.index {
+		if e.granule >= 0 {
+			granules = append(granules, i)
+		}
+	}
+	k := 0
+	for k < len(granules) && d.index[granules[k]].granule-d.codec.preSkip() <= start {
+		k++
+	}
+	k = min(k, len(granules)-2)
+
+	d.codec.reset()
+	d.buffer = d.buffer[:0]
+	d.anchored = false
+	d.eof = false
+	d.pos = target
+	if k <= 0 {
+		d.fromStart = true
+		return npos, d.reader.seek(d.audioStart)
+	}
+	d.fromStart = false
+	page := granules[k-1] + 1
+	return npos, d.reader.seek(d.index[page].offset)
+}
+
+// Decode converts a single interleaved frame of little-endian 32 bit float samples, i.e., the
+// layout of the stream decoded to a float WAV file, to a pair of float64 samples for the left
+// and right channel, and returns the number of bytes consumed.
+func (d *Decoder) Decode(p []byte) (sample [2]float64, n int) {
+	for c := range d.scratch {
+		d.scratch[c] = float64(math.Float32frombits(binary.LittleEndian.Uint32(p[4*c:])))
+	}
+	return decoder.Fold(d.scratch), 4 * len(d.scratch)
+}
+
+// Close is a no-op, the underlying source is owned by the caller
+func (d *Decoder) Close() error {
+	return nil
+}
@@ -0,0 +1,412 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ogg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zoomoid/waveman2/pkg/decoder"
+)
+
+// testPacket is an audio packet of a test stream with the granule position at its end
+type testPacket struct {
+	data    []byte
+	granule int64
+}
+
+// encode packs header and audio packets into Ogg pages of at most maxSegments segments each,
+// starting a fresh page for the first audio packet
+func encode(serial uint32, headers [][]byte, packets []testPacket, maxSegments int) []byte {
+	out := &bytes.Buffer{}
+	sequence := uint32(0)
+	writePage := func(flags byte, granule int64, lacing []byte, body []byte) {
+		header := make([]byte, headerSize)
+		copy(header, capturePattern)
+		header[5] = flags
+		binary.LittleEndian.PutUint64(header[6:14], uint64(granule))
+		binary.LittleEndian.PutUint32(header[14:18], serial)
+		binary.LittleEndian.PutUint32(header[18:22], sequence)
+		header[26] = byte(len(lacing))
+		crc := crc32(crc32(crc32(0, header), lacing), body)
+		binary.LittleEndian.PutUint32(header[22:26], crc)
+		out.Write(header)
+		out.Write(lacing)
+		out.Write(body)
+		sequence++
+	}
+
+	for i, h := range headers {
+		var lacing []byte
+		for n := len(h); ; n -= 255 {
+			if n < 255 {
+				lacing = append(lacing, byte(n))
+				break
+			}
+			lacing = append(lacing, 255)
+		}
+		flags := byte(0)
+		if i == 0 {
+			flags = flagBOS
+		}
+		writePage(flags, 0, lacing, h)
+	}
+
+	var lacing, body []byte
+	granule := int64(-1)
+	continued := false
+	for i, p := range packets {
+		data := p.data
+		for {
+			n := min(len(data), 255)
+			lacing = append(lacing, byte(n))
+			body = append(body, data[:n]...)
+			data = data[n:]
+			done := n < 255
+			if done {
+				granule = p.granule
+			}
+			last := done && i == len(packets)-1
+			if len(lacing) == maxSegments || last {
+				flags := byte(0)
+				if continued {
+					flags |= flagContinued
+				}
+				if last {
+					flags |= flagEOS
+				}
+				writePage(flags, granule, lacing, body)
+				lacing, body, granule = nil, nil, -1
+				continued = !done
+			}
+			if done {
+				break
+			}
+		}
+	}
+	return out.Bytes()
+}
+
+const fakeMagic = "\x7ffake"
+
+// fakeCodec emits the samples encoded in its packets, which hold the index of the first sample
+// and the number of samples. Like Vorbis, the first packet after a reset only primes the codec.
+type fakeCodec struct {
+	skip   int64
+	primed bool
+}
+
+func init() {
+	codecs[fakeMagic] = func() codec { return &fakeCodec{} }
+}
+
+func (c *fakeCodec) header(packet []byte) (bool, error) {
+	c.skip = int64(packet[len(fakeMagic)])
+	return true, nil
+}
+
+func (c *fakeCodec) sampleRate() int { return 8000 }
+func (c *fakeCodec) channels() int   { return 1 }
+func (c *fakeCodec) preSkip() int64  { return c.skip }
+func (c *fakeCodec) preRoll() int64  { return 2 * testPacketSamples }
+func (c *fakeCodec) reset()          { c.primed = false }
+
+func (c *fakeCodec) decode(packet []byte, out [][2]float64) ([][2]float64, error) {
+	if !c.primed {
+		c.primed = true
+		return out, nil
+	}
+	start := binary.LittleEndian.Uint32(packet[0:4])
+	count := binary.LittleEndian.Uint16(packet[4:6])
+	for i := uint32(0); i < uint32(count); i++ {
+		out = append(out, [2]float64{float64(start + i), float64(start + i)})
+	}
+	return out, nil
+}
+
+const (
+	testPacketSamples = 100
+	testPackets       = 50
+	testPreSkip       = 10
+	testTrim          = 30
+	// testSamples is the number of samples after discarding pre-skip and trimmed samples
+	testSamples = (testPackets-1)*testPacketSamples - testPreSkip - testTrim
+)
+
+// fakeStream returns a stream whose samples hold their own position plus testPreSkip, with
+// packets of 300 bytes spanning pages of 5 segments
+func fakeStream() []byte {
+	packets := make([]testPacket, testPackets)
+	for i := range packets {
+		data := make([]byte, 300)
+		// the first packet primes the codec
+		if i > 0 {
+			binary.LittleEndian.PutUint32(data[0:4], uint32((i-1)*testPacketSamples))
+			binary.LittleEndian.PutUint16(data[4:6], testPacketSamples)
+			packets[i].granule = int64(i * testPacketSamples)
+		}
+		packets[i].data = data
+	}
+	packets[len(packets)-1].granule -= testTrim
+	return encode(1, [][]byte{[]byte(fakeMagic + "\x0a")}, packets, 5)
+}
+
+func TestDecoderRead(t *testing.T) {
+	raw := fakeStream()
+	for name, r := range map[string]io.Reader{
+		"seekable":     bytes.NewReader(raw),
+		"non-seekable": io.MultiReader(bytes.NewReader(raw)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			d, err := NewDecoder(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name == "seekable" && d.Length() != testSamples*decoder.FrameWidth {
+				t.Errorf("expected length %d, found %d", testSamples*decoder.FrameWidth, d.Length())
+			}
+			samples := make([][2]float64, testSamples+100)
+			n, err := d.Read(samples)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != testSamples {
+				t.Fatalf("expected %d samples, found %d", testSamples, n)
+			}
+			for i, s := range samples[:n] {
+				if s[0] != float64(i+testPreSkip) {
+					t.Fatalf("sample %d: expected %d, found %g", i, i+testPreSkip, s[0])
+				}
+			}
+		})
+	}
+}
+
+func TestDecoderSeek(t *testing.T) {
+	d, err := NewDecoder(bytes.NewReader(fakeStream()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := make([][2]float64, 10)
+	for _, target := range []int64{2000, 1999, 17, 0, 4321, 4400, testSamples - 5, 300} {
+		if _, err := d.Seek(target*int64(decoder.FrameWidth), io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		n, err := d.Read(samples)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != min(10, testSamples-int(target)) {
+			t.Fatalf("expected %d samples after seeking to %d, found %d", min(10, testSamples-int(target)), target, n)
+		}
+		for i, s := range samples[:n] {
+			if s[0] != float64(target+int64(i)+testPreSkip) {
+				t.Fatalf("after seeking to %d: expected sample %d, found %g", target, target+int64(i)+testPreSkip, s[0])
+			}
+		}
+	}
+	if _, err := d.Seek(int64(d.Length()+decoder.FrameWidth), io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := d.Read(samples); n != 0 {
+		t.Errorf("expected no samples after seeking past the end, found %d", n)
+	}
+
+	// non-seekable sources only support seeking forwards
+	d, err = NewDecoder(io.MultiReader(bytes.NewReader(fakeStream())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Seek(int64(1234*decoder.FrameWidth), io.SeekCurrent); err != nil {
+		t.Fatal(err)
+	}
+	d.Read(samples[:1])
+	if samples[0][0] != 1234+testPreSkip {
+		t.Errorf("expected %d after seeking to 1234, found %g", 1234+testPreSkip, samples[0][0])
+	}
+	if _, err := d.Seek(0, io.SeekStart); err == nil {
+		t.Error("expected error when seeking backwards in a non-seekable source")
+	}
+}
+
+func TestMultiplexed(t *testing.T) {
+	// pages of other logical bitstreams and corrupt pages are skipped
+	other := encode(2, [][]byte{[]byte("\x00other")}, []testPacket{{data: make([]byte, 600)}}, 2)
+	raw := fakeStream()
+	corrupt := append([]byte(nil), raw...)
+	corrupt[len(corrupt)-1] ^= 0xff
+
+	split := len(raw)/2 + bytes.Index(raw[len(raw)/2:], []byte(capturePattern))
+	stream := append(append([]byte(nil), raw[:split]...), other...)
+	stream = append(stream, raw[split:]...)
+
+	d, err := NewDecoder(bytes.NewReader(stream))
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := make([][2]float64, testSamples)
+	if n, _ := d.Read(samples); n == 0 || samples[n-1][0] != float64(n-1+testPreSkip) {
+		t.Errorf("expected contiguous samples despite interleaved pages of another stream")
+	}
+
+	d, err = NewDecoder(bytes.NewReader(corrupt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := d.Read(samples); err != nil || n >= testSamples {
+		t.Errorf("expected the corrupt last page to be dropped, found %d samples and %v", n, err)
+	}
+}
+
+func TestNotOgg(t *testing.T) {
+	if _, err := NewDecoder(bytes.NewReader([]byte("RIFF\x00\x00\x00\x00WAVEfmt "))); err != ErrNotOgg {
+		t.Errorf("expected ErrNotOgg, found %v", err)
+	}
+	stream := encode(1, [][]byte{[]byte("\x80theora")}, nil, 5)
+	if _, err := NewDecoder(bytes.NewReader(stream)); err != ErrUnsupportedCodec {
+		t.Errorf("expected ErrUnsupportedCodec, found %v", err)
+	}
+}
+
+// opusPacket is a single 20ms SILK packet
+var opusPacket = []byte{0x48, 0x83, 0xca, 0xde, 0x8a, 0xe5, 0x67, 0xd5, 0x1c, 0xac, 0xa2, 0x54, 0xfa, 0xff, 0xbf}
+
+func TestOpus(t *testing.T) {
+	const (
+		preSkip = 312
+		frames  = 20
+		trim    = 100
+	)
+	head := []byte("OpusHead\x01\x02")
+	head = binary.LittleEndian.AppendUint16(head, preSkip)
+	head = binary.LittleEndian.AppendUint32(head, 48000)
+	head = append(head, 0, 0, 0)
+	packets := make([]testPacket, frames)
+	for i := range packets {
+		packets[i] = testPacket{data: opusPacket, granule: int64((i + 1) * 960)}
+	}
+	packets[frames-1].granule -= trim
+	stream := encode(7, [][]byte{head, []byte("OpusTags")}, packets, 8)
+
+	d, err := NewDecoder(bytes.NewReader(stream))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := frames*960 - preSkip - trim
+	if d.SampleRate() != 48000 || d.Channels() != 2 {
+		t.Errorf("expected 2 channels at 48kHz, found %d at %dHz", d.Channels(), d.SampleRate())
+	}
+	if d.Length() != expected*decoder.FrameWidth {
+		t.Fatalf("expected length %d, found %d", expected*decoder.FrameWidth, d.Length())
+	}
+	samples := make([][2]float64, expected+1)
+	n, err := d.Read(samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != expected {
+		t.Fatalf("expected %d samples, found %d", expected, n)
+	}
+	for i, s := range samples[:n] {
+		if math.IsNaN(s[0]) || math.Abs(s[0]) > 2 {
+			t.Fatalf("sample %d out of range: %v", i, s)
+		}
+	}
+
+	if _, err := d.Seek(int64(15000*decoder.FrameWidth), io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := d.Read(samples); err != nil || n != expected-15000 {
+		t.Errorf("expected %d samples after seeking, found %d (%v)", expected-15000, n, err)
+	}
+}
+
+// readReference reads interleaved 32 bit little-endian float PCM from testdata
+func readReference(t *testing.T, name string) []float64 {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reference := make([]float64, len(raw)/4)
+	for i := range reference {
+		reference[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(raw[4*i:])))
+	}
+	return reference
+}
+
+// TestFixtures decodes streams encoded by libvorbis and libopus and compares them to reference
+// PCM decoded by independent implementations. vorbis_stereo.raw only covers the first 16384
+// samples of its stream, and was decoded by a decoder clipping its output to [-1,1].
+func TestFixtures(t *testing.T) {
+	const tolerance = 1e-4
+	fixtures := []struct {
+		name       string
+		sampleRate int
+		channels   int
+		samples    int
+	}{
+		{name: "vorbis_mono", sampleRate: 44100, channels: 1, samples: 44100},
+		{name: "vorbis_stereo", sampleRate: 44100, channels: 2, samples: 72384},
+		{name: "opus_mono", sampleRate: 48000, channels: 1, samples: 279},
+	}
+	for _, fixture := range fixtures {
+		t.Run(fixture.name, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", fixture.name+".ogg"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			d, err := NewDecoder(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.SampleRate() != fixture.sampleRate || d.Channels() != fixture.channels {
+				t.Errorf("expected %d channels at %dHz, found %d at %dHz", fixture.channels, fixture.sampleRate, d.Channels(), d.SampleRate())
+			}
+			if d.Length() != fixture.samples*decoder.FrameWidth {
+				t.Errorf("expected length %d, found %d", fixture.samples*decoder.FrameWidth, d.Length())
+			}
+
+			samples := make([][2]float64, fixture.samples+1)
+			n, err := d.Read(samples)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != fixture.samples {
+				t.Fatalf("expected %d samples, found %d", fixture.samples, n)
+			}
+
+			reference := readReference(t, fixture.name+".raw")
+			for i := 0; i < len(reference)/fixture.channels; i++ {
+				for c := 0; c < fixture.channels; c++ {
+					expected := reference[i*fixture.channels+c]
+					found := math.Max(-1, math.Min(1, samples[i][c]))
+					if diff := math.Abs(found - expected); diff > tolerance {
+						t.Fatalf("sample %d of channel %d: expected %f, found %f", i, c, expected, found)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ogg
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/pion/opus"
+	"github.com/zoomoid/waveman2/pkg/decoder"
+)
+
+const (
+	// opusSampleRate is the rate of all granule positions in Ogg Opus streams
+	opusSampleRate = 48000
+	// opusMaxFrameSize is the number of samples per channel of the longest Opus packet, 120ms
+	opusMaxFrameSize = 5760
+	// opusPreRoll is the number of samples RFC 7845 recommends to decode before a seek target
+	opusPreRoll = 3840
+)
+
+// opusCodec decodes Ogg Opus streams as specified in RFC 7845
+type opusCodec struct {
+	decoder     opus.Decoder
+	headers     int
+	numChannels int
+	skip        int64
+	gain        float64
+
+	pcm     []float32
+	scratch []float64
+}
+
+func newOpusCodec() *opusCodec {
+	return &opusCodec{}
+}
+
+func (c *opusCodec) header(packet []byte) (bool, error) {
+	c.headers++
+	if c.headers == 2 {
+		// the comment header carries no information relevant for decoding
+		if len(packet) < 8 || string(packet[:8]) != "OpusTags" {
+			return false, errors.New("ogg: missing OpusTags header")
+		}
+		return true, nil
+	}
+
+	if len(packet) < 19 {
+		return false, errors.New("ogg: OpusHead header too short")
+	}
+	if version := packet[8]; version>>4 != 0 {
+		return false, fmt.Errorf("ogg: opus version %d is not supported", version)
+	}
+	c.numChannels = int(packet[9])
+	c.skip = int64(binary.LittleEndian.Uint16(packet[10:12]))
+	// the output gain is stored in Q7.8 dB
+	c.gain = math.Pow(10, float64(int16(binary.LittleEndian.Uint16(packet[16:18])))/(20*256))
+	family := packet[18]
+	if c.numChannels == 0 {
+		return false, errors.New("ogg: opus stream has no channels")
+	}
+	if c.numChannels > 2 || (family != 0 && (len(packet) < 21 || packet[19] != 1)) {
+		return false, fmt.Errorf("ogg: multistream opus with %d channels (mapping family %d) is not supported", c.numChannels, family)
+	}
+
+	var err error
+	c.decoder, err = opus.NewDecoderWithOutput(opusSampleRate, c.numChannels)
+	if err != nil {
+		return false, err
+	}
+	c.pcm = make([]float32, opusMaxFrameSize*c.numChannels)
+	c.scratch = make([]float64, c.numChannels)
+	return false, nil
+}
+
+func (c *opusCodec) sampleRate() int {
+	return opusSampleRate
+}
+
+func (c *opusCodec) channels() int {
+	return c.numChannels
+}
+
+func (c *opusCodec) preSkip() int64 {
+	return c.skip
+}
+
+// preRoll covers the recommended pre-roll plus a continued packet, which is dropped after seeking
+func (c *opusCodec) preRoll() int64 {
+	return opusPreRoll + opusMaxFrameSize
+}
+
+func (c *opusCodec) decode(packet []byte, out [][2]float64) ([][2]float64, error) {
+	n, err := c.decoder.DecodeToFloat32(packet, c.pcm)
+	if err != nil {
+		return out, fmt.Errorf("ogg: %w", err)
+	}
+	for i := 0; i < n; i++ {
+		for ch := range c.scratch {
+			c.scratch[ch] = float64(c.pcm[i*c.numChannels+ch]) * c.gain
+		}
+		out = append(out, decoder.Fold(c.scratch))
+	}
+	return out, nil
+}
+
+func (c *opusCodec) reset() {
+	c.decoder.Init(opusSampleRate, c.numChannels)
+}
@@ -0,0 +1,323 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ogg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const (
+	capturePattern = "OggS"
+	headerSize     = 27
+
+	flagContinued byte = 0x01
+	flagBOS       byte = 0x02
+	flagEOS       byte = 0x04
+)
+
+var ErrNotOgg error = errors.New("ogg: missing capture pattern")
+
+// page is a single Ogg page of a logical bitstream
+type page struct {
+	// offset is the absolute offset of the page in the source
+	offset   int64
+	flags    byte
+	granule  int64
+	serial   uint32
+	sequence uint32
+	lacing   []byte
+	// length is the size of the body, which is nil when only the header was read
+	length int
+	body   []byte
+}
+
+// size returns the total size of the page in bytes
+func (p *page) size() int64 {
+	return int64(headerSize + len(p.lacing) + p.length)
+}
+
+// readPage reads the next page from r. If the reader is not positioned at a capture pattern,
+// readPage returns ErrNotOgg without consuming any input. If body is false, only the header and
+// lacing values are read and the body is skipped without verifying the checksum.
+func readPage(r *bufio.Reader, offset int64, body bool) (*page, error) {
+	header, err := r.Peek(headerSize)
+	if len(header) >= len(capturePattern) && string(header[:len(capturePattern)]) != capturePattern {
+		return nil, ErrNotOgg
+	}
+	if err != nil {
+		return nil, io.EOF
+	}
+	if header[4] != 0 {
+		return nil, ErrNotOgg
+	}
+	header = append([]byte(nil), header...)
+	r.Discard(headerSize)
+
+	p := &page{
+		offset:   offset,
+		flags:    header[5],
+		granule:  int64(binary.LittleEndian.Uint64(header[6:14])),
+		serial:   binary.LittleEndian.Uint32(header[14:18]),
+		sequence: binary.LittleEndian.Uint32(header[18:22]),
+		lacing:   make([]byte, header[26]),
+	}
+	if _, err := io.ReadFull(r, p.lacing); err != nil {
+		return nil, io.EOF
+	}
+	for _, l := range p.lacing {
+		p.length += int(l)
+	}
+	if !body {
+		if _, err := r.Discard(p.length); err != nil {
+			return nil, io.EOF
+		}
+		return p, nil
+	}
+	p.body = make([]byte, p.length)
+	if _, err := io.ReadFull(r, p.body); err != nil {
+		return nil, io.EOF
+	}
+
+	checksum := binary.LittleEndian.Uint32(header[22:26])
+	binary.LittleEndian.PutUint32(header[22:26], 0)
+	crc := crc32(0, header)
+	crc = crc32(crc, p.lacing)
+	crc = crc32(crc, p.body)
+	if crc != checksum {
+		return p, errChecksum
+	}
+	return p, nil
+}
+
+var errChecksum = errors.New("ogg: page checksum mismatch")
+
+// crcTable holds the CRC-32 lookup table of the Ogg framing, which uses the polynomial
+// 0x04c11db7 without bit reflection
+var crcTable = func() (t [256]uint32) {
+	for i := range t {
+		r := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		t[i] = r
+	}
+	return t
+}()
+
+func crc32(crc uint32, p []byte) uint32 {
+	for _, b := range p {
+		crc = crc<<8 ^ crcTable[byte(crc>>24)^b]
+	}
+	return crc
+}
+
+// packet is a single packet of a logical bitstream
+type packet struct {
+	data []byte
+	// granule is the granule position of the page the packet completes on if it is the last
+	// packet completing on that page, and -1 otherwise
+	granule int64
+	// eos is set for the last packet of the logical bitstream
+	eos bool
+}
+
+// packetReader reassembles the packets of the first logical bitstream found in an Ogg source,
+// ignoring pages of all other multiplexed bitstreams. Reading stops at the end of the first
+// logical bitstream, chained streams are not supported.
+type packetReader struct {
+	source io.Reader
+	r      *bufio.Reader
+	// offset is the absolute offset of the next page to be read
+	offset int64
+
+	serial    uint32
+	hasSerial bool
+
+	page *page
+	// segment is the index of the next lacing value of the current page
+	segment int
+	// position is the offset of the next segment in the current page's body
+	position int
+	// last is the index of the last segment completing a packet on the current page
+	last    int
+	partial []byte
+
+	// skipContinued drops the continued packet at the start of the next page, e.g., after seeking
+	// into the middle of the stream
+	skipContinued bool
+	eos           bool
+}
+
+func newPacketReader(source io.Reader) *packetReader {
+	return &packetReader{
+		source: source,
+		r:      bufio.NewReader(source),
+	}
+}
+
+// next returns the next complete packet or io.EOF at the end of the logical bitstream
+func (r *packetReader) next() (packet, error) {
+	for {
+		if r.page == nil || r.segment >= len(r.page.lacing) {
+			if r.eos {
+				return packet{}, io.EOF
+			}
+			if err := r.nextPage(); err != nil {
+				return packet{}, err
+			}
+			continue
+		}
+
+		l := int(r.page.lacing[r.segment])
+		r.partial = append(r.partial, r.page.body[r.position:r.position+l]...)
+		r.position += l
+		r.segment++
+		if l == 255 {
+			// the packet continues in the next segment, possibly on the next page
+			continue
+		}
+
+		p := packet{data: r.partial, granule: -1}
+		r.partial = nil
+		if r.segment-1 == r.last {
+			p.granule = r.page.granule
+			p.eos = r.page.flags&flagEOS != 0
+		}
+		return p, nil
+	}
+}
+
+// nextPage reads the next page of the logical bitstream, skipping pages of other bitstreams and
+// resynchronizing on corrupt pages
+func (r *packetReader) nextPage() error {
+	for {
+		p, err := readPage(r.r, r.offset, true)
+		switch {
+		case errors.Is(err, ErrNotOgg) && r.hasSerial:
+			// drop any partial packet and scan for the next capture pattern
+			r.partial = nil
+			r.skipContinued = true
+			if err := r.resync(); err != nil {
+				return err
+			}
+			continue
+		case errors.Is(err, errChecksum):
+			r.offset += p.size()
+			r.partial = nil
+			r.skipContinued = true
+			continue
+		case err != nil:
+			return err
+		}
+		r.offset += p.size()
+
+		if !r.hasSerial {
+			r.serial = p.serial
+			r.hasSerial = true
+		}
+		if p.serial != r.serial {
+			continue
+		}
+
+		r.page = p
+		r.segment = 0
+		r.position = 0
+		r.last = -1
+		for i, l := range p.lacing {
+			if l < 255 {
+				r.last = i
+			}
+		}
+		r.eos = p.flags&flagEOS != 0
+
+		if r.skipContinued {
+			r.skipContinued = false
+			if p.flags&flagContinued != 0 {
+				r.partial = nil
+				r.skipSegments()
+			}
+		}
+		if p.flags&flagContinued == 0 {
+			// a fresh packet starts on this page, so any partial packet is incomplete
+			r.partial = nil
+		}
+		return nil
+	}
+}
+
+// skipSegments drops the segments of the continued packet at the start of the current page
+func (r *packetReader) skipSegments() {
+	for r.segment < len(r.page.lacing) {
+		l := int(r.page.lacing[r.segment])
+		r.position += l
+		r.segment++
+		if l < 255 {
+			return
+		}
+	}
+	// the packet continues on the next page, too
+	r.skipContinued = true
+}
+
+// resync advances the reader to the next capture pattern
+func (r *packetReader) resync() error {
+	pattern := []byte(capturePattern)
+	if _, err := r.r.Discard(1); err != nil {
+		return io.EOF
+	}
+	r.offset++
+	for {
+		peek, err := r.r.Peek(len(pattern))
+		if err != nil {
+			return io.EOF
+		}
+		if bytes.Equal(peek, pattern) {
+			return nil
+		}
+		if _, err := r.r.Discard(1); err != nil {
+			return io.EOF
+		}
+		r.offset++
+	}
+}
+
+// seek positions the reader at the page at offset, which has to be a page of the logical
+// bitstream. The continued packet at the start of the page is dropped.
+func (r *packetReader) seek(offset int64) error {
+	s, ok := r.source.(io.Seeker)
+	if !ok {
+		return errors.New("ogg: source is not seekable")
+	}
+	if _, err := s.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	r.r.Reset(r.source)
+	r.offset = offset
+	r.page = nil
+	r.partial = nil
+	r.skipContinued = true
+	r.eos = false
+	return nil
+}
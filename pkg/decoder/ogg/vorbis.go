@@ -0,0 +1,89 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ogg
+
+import (
+	"github.com/jfreymuth/vorbis"
+	"github.com/zoomoid/waveman2/pkg/decoder"
+)
+
+// vorbisCodec decodes Ogg Vorbis streams with github.com/jfreymuth/vorbis
+type vorbisCodec struct {
+	decoder *vorbis.Decoder
+	buffer  []float32
+	scratch []float64
+}
+
+func newVorbisCodec() *vorbisCodec {
+	return &vorbisCodec{
+		decoder: &vorbis.Decoder{},
+	}
+}
+
+func (c *vorbisCodec) header(packet []byte) (bool, error) {
+	if err := c.decoder.ReadHeader(packet); err != nil {
+		return false, err
+	}
+	if !c.decoder.HeadersRead() {
+		return false, nil
+	}
+	c.buffer = make([]float32, c.decoder.BufferSize())
+	c.scratch = make([]float64, c.decoder.Channels())
+	return true, nil
+}
+
+func (c *vorbisCodec) sampleRate() int {
+	return c.decoder.SampleRate()
+}
+
+func (c *vorbisCodec) channels() int {
+	return c.decoder.Channels()
+}
+
+func (c *vorbisCodec) preSkip() int64 {
+	return 0
+}
+
+// preRoll covers the continued packet dropped after seeking and the first decoded packet, which
+// only primes the overlap. Both span at most a long block, i.e., twice the buffer size per
+// channel.
+func (c *vorbisCodec) preRoll() int64 {
+	return int64(2 * c.decoder.BufferSize() / c.decoder.Channels())
+}
+
+func (c *vorbisCodec) decode(packet []byte, out [][2]float64) ([][2]float64, error) {
+	// empty packets and stray headers carry no audio
+	if len(packet) == 0 || packet[0]&1 == 1 {
+		return out, nil
+	}
+	pcm, err := c.decoder.DecodeInto(packet, c.buffer)
+	if err != nil {
+		return out, err
+	}
+	channels := len(c.scratch)
+	for i := 0; i+channels <= len(pcm); i += channels {
+		for ch := range c.scratch {
+			c.scratch[ch] = float64(pcm[i+ch])
+		}
+		out = append(out, decoder.Fold(c.scratch))
+	}
+	return out, nil
+}
+
+func (c *vorbisCodec) reset() {
+	c.decoder.Clear()
+}
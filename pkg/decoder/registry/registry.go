@@ -0,0 +1,219 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package registry maps audio formats to their decoders, such that library users and the CLI
+// share a single path for selecting a decoder. Formats are identified by name, by file extension,
+// or by the magic bytes at the start of a file.
+package registry
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/zoomoid/waveman2/pkg/decoder"
+	"github.com/zoomoid/waveman2/pkg/decoder/flac"
+	mp3 "github.com/zoomoid/waveman2/pkg/decoder/go-mp3"
+	"github.com/zoomoid/waveman2/pkg/decoder/ogg"
+	"github.com/zoomoid/waveman2/pkg/decoder/wav"
+)
+
+var ErrUnknownFormat error = errors.New("unknown audio format")
+
+// SniffLength is the number of bytes read from the start of a source to detect its format
+const SniffLength = 64
+
+// Format describes an audio format and how to construct a decoder for it
+type Format struct {
+	// Name identifies the format, e.g., "mp3"
+	Name string
+	// Extensions lists the file extensions of the format including the leading dot
+	Extensions []string
+	// Magic lists byte signatures any of which identifies the format at the start of a file
+	Magic [][]byte
+	// Match optionally identifies the format from the first SniffLength bytes of a file for
+	// formats that cannot be described by fixed signatures, e.g., raw MPEG audio frames
+	Match func(header []byte) bool
+	// New constructs a decoder reading from a source of the format
+	New func(f io.Reader) (decoder.Decoder, error)
+}
+
+// formats holds all registered formats in order of registration
+var formats []*Format
+
+func init() {
+	Register(&Format{
+		Name:       "mp3",
+		Extensions: []string{".mp3"},
+		Magic:      [][]byte{[]byte("ID3")},
+		Match:      isMPEGFrame,
+		New: func(f io.Reader) (decoder.Decoder, error) {
+			return mp3.NewDecoder(f)
+		},
+	})
+	Register(&Format{
+		Name:       "wav",
+		Extensions: []string{".wav", ".wave"},
+		Match:      isWAVE,
+		New: func(f io.Reader) (decoder.Decoder, error) {
+			return wav.NewDecoder(f)
+		},
+	})
+	Register(&Format{
+		Name:       "flac",
+		Extensions: []string{".flac"},
+		Magic:      [][]byte{[]byte("fLaC")},
+		New: func(f io.Reader) (decoder.Decoder, error) {
+			return flac.NewDecoder(f)
+		},
+	})
+	Register(&Format{
+		Name:       "ogg",
+		Extensions: []string{".ogg", ".oga", ".opus"},
+		Magic:      [][]byte{[]byte("OggS")},
+		New: func(f io.Reader) (decoder.Decoder, error) {
+			return ogg.NewDecoder(f)
+		},
+	})
+}
+
+// Register adds a format to the registry, replacing any format registered under the same name.
+// Register is not safe for concurrent use and is meant to be called from init functions.
+func Register(format *Format) {
+	for i, f := range formats {
+		if f.Name == format.Name {
+			formats[i] = format
+			return
+		}
+	}
+	formats = append(formats, format)
+}
+
+// Lookup returns the format registered under name
+func Lookup(name string) (*Format, error) {
+	for _, f := range formats {
+		if f.Name == name {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("%w %s", ErrUnknownFormat, name)
+}
+
+// FromExtension returns the format registered for a file extension such as ".wav", ignoring case
+func FromExtension(ext string) (*Format, error) {
+	ext = strings.ToLower(ext)
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	for _, f := range formats {
+		for _, e := range f.Extensions {
+			if e == ext {
+				return f, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w for extension %q", ErrUnknownFormat, ext)
+}
+
+// FromMagic returns the format whose magic bytes match the start of header. Fixed signatures
+// take precedence over the formats' Match functions, such that e.g. an MPEG frame sync does
+// not shadow a more specific signature.
+func FromMagic(header []byte) (*Format, error) {
+	for _, f := range formats {
+		for _, m := range f.Magic {
+			if bytes.HasPrefix(header, m) {
+				return f, nil
+			}
+		}
+	}
+	for _, f := range formats {
+		if f.Match != nil && f.Match(header) {
+			return f, nil
+		}
+	}
+	return nil, ErrUnknownFormat
+}
+
+// Sniff reads the first SniffLength bytes of r and returns the format detected by FromMagic,
+// together with a reader that yields the entire source again. When r is io.Seeker, it is
+// rewound and returned as is, so decoders can still seek in it. Otherwise, the returned reader
+// replays the consumed header before continuing with r.
+func Sniff(r io.Reader) (*Format, io.Reader, error) {
+	var start int64 = -1
+	if s, ok := r.(io.Seeker); ok {
+		// Seek fails for e.g. pipes, which are then treated as non-seekable
+		if pos, err := s.Seek(0, io.SeekCurrent); err == nil {
+			start = pos
+		}
+	}
+
+	header := make([]byte, SniffLength)
+	n, err := io.ReadFull(r, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, r, err
+	}
+	header = header[:n]
+
+	if start >= 0 {
+		if _, err := r.(io.Seeker).Seek(start, io.SeekStart); err != nil {
+			return nil, r, err
+		}
+	} else {
+		r = io.MultiReader(bytes.NewReader(header), r)
+	}
+
+	f, err := FromMagic(header)
+	return f, r, err
+}
+
+// isMPEGFrame reports whether header starts with a valid MPEG audio frame header, i.e., an
+// mp3 file without ID3v2 tag
+func isMPEGFrame(header []byte) bool {
+	if len(header) < 4 || header[0] != 0xff || header[1]&0xe0 != 0xe0 {
+		return false
+	}
+	version := (header[1] >> 3) & 0x3
+	layer := (header[1] >> 1) & 0x3
+	bitrate := header[2] >> 4
+	sampleRate := (header[2] >> 2) & 0x3
+	// reserved values, and free format bitrates which the mp3 decoder does not support
+	return version != 1 && layer != 0 && bitrate != 0 && bitrate != 0xf && sampleRate != 3
+}
+
+// isWAVE reports whether header starts with a RIFF chunk of form type WAVE
+func isWAVE(header []byte) bool {
+	return len(header) >= 12 && bytes.Equal(header[0:4], []byte("RIFF")) && bytes.Equal(header[8:12], []byte("WAVE"))
+}
+
+// Names returns the names of all registered formats
+func Names() []string {
+	names := make([]string, 0, len(formats))
+	for _, f := range formats {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// Extensions returns the file extensions of all registered formats
+func Extensions() []string {
+	var extensions []string
+	for _, f := range formats {
+		extensions = append(extensions, f.Extensions...)
+	}
+	return extensions
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/zoomoid/waveman2/pkg/decoder"
+)
+
+func TestLookup(t *testing.T) {
+	for _, name := range []string{"mp3", "wav", "flac", "ogg"} {
+		if _, err := Lookup(name); err != nil {
+			t.Errorf("expected format %s to be registered, found %v", name, err)
+		}
+	}
+	if _, err := Lookup("aiff"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("expected ErrUnknownFormat, found %v", err)
+	}
+}
+
+func TestFromExtension(t *testing.T) {
+	cases := map[string]string{
+		".mp3":  "mp3",
+		".WAV":  "wav",
+		"flac":  "flac",
+		".opus": "ogg",
+	}
+	for ext, name := range cases {
+		f, err := FromExtension(ext)
+		if err != nil {
+			t.Errorf("%s: %v", ext, err)
+			continue
+		}
+		if f.Name != name {
+			t.Errorf("%s: expected format %s, found %s", ext, name, f.Name)
+		}
+	}
+	if _, err := FromExtension(".txt"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("expected ErrUnknownFormat, found %v", err)
+	}
+}
+
+func TestFromMagic(t *testing.T) {
+	cases := map[string]string{
+		"ID3\x04\x00":                  "mp3",
+		"\xff\xfb\x90\x64":             "mp3",
+		"RIFF\x24\x00\x00\x00WAVEfmt ": "wav",
+		"fLaC\x00":                     "flac",
+		"OggS\x00\x02":                 "ogg",
+	}
+	for header, name := range cases {
+		f, err := FromMagic([]byte(header))
+		if err != nil {
+			t.Errorf("%q: %v", header, err)
+			continue
+		}
+		if f.Name != name {
+			t.Errorf("%q: expected format %s, found %s", header, name, f.Name)
+		}
+	}
+	for _, header := range []string{
+		"MThd",
+		// RIFF containers other than WAVE
+		"RIFF\x24\x00\x00\x00AVI LIST",
+		// frame sync with reserved layer and free format bitrate
+		"\xff\xf9\x90\x64",
+		"\xff\xfb\x00\x64",
+	} {
+		if _, err := FromMagic([]byte(header)); !errors.Is(err, ErrUnknownFormat) {
+			t.Errorf("%q: expected ErrUnknownFormat, found %v", header, err)
+		}
+	}
+}
+
+func TestSniff(t *testing.T) {
+	data := append([]byte("fLaC"), bytes.Repeat([]byte{0x42}, 2*SniffLength)...)
+	for name, r := range map[string]io.Reader{
+		"seekable":     bytes.NewReader(data),
+		"non-seekable": io.MultiReader(bytes.NewReader(data)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			f, rest, err := Sniff(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f.Name != "flac" {
+				t.Errorf("expected format flac, found %s", f.Name)
+			}
+			// the returned reader yields the whole source, including the sniffed header
+			b, err := io.ReadAll(rest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(b, data) {
+				t.Errorf("expected %d bytes of the original source, found %d", len(data), len(b))
+			}
+		})
+	}
+
+	// sources shorter than SniffLength are sniffed as well
+	if f, _, err := Sniff(bytes.NewReader([]byte("OggS"))); err != nil || f.Name != "ogg" {
+		t.Errorf("expected format ogg, found %v (%v)", f, err)
+	}
+	if _, _, err := Sniff(bytes.NewReader(nil)); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("expected ErrUnknownFormat for an empty source, found %v", err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	defer func(saved []*Format) { formats = saved }(append([]*Format(nil), formats...))
+
+	custom := &Format{
+		Name:       "raw",
+		Extensions: []string{".raw"},
+		New:        func(f io.Reader) (decoder.Decoder, error) { return nil, nil },
+	}
+	Register(custom)
+	if f, err := FromExtension(".raw"); err != nil || f != custom {
+		t.Errorf("expected custom format for .raw, found %v (%v)", f, err)
+	}
+
+	// registering a format under an existing name replaces it
+	replacement := &Format{Name: "raw", Extensions: []string{".pcm"}}
+	Register(replacement)
+	if _, err := FromExtension(".raw"); err == nil {
+		t.Error("expected .raw to be unregistered after replacing the format")
+	}
+	if f, _ := Lookup("raw"); f != replacement {
+		t.Error("expected the replacement format")
+	}
+}
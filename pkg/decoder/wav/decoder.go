@@ -0,0 +1,345 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/zoomoid/waveman2/pkg/decoder"
+)
+
+const (
+	formatPCM        uint16 = 0x0001
+	formatIEEEFloat  uint16 = 0x0003
+	formatExtensible uint16 = 0xFFFE
+
+	// unknownDataSize is written by some streaming encoders into the data chunk header
+	// when the total size is not known in advance
+	unknownDataSize uint32 = 0xFFFFFFFF
+)
+
+var (
+	ErrNotRiff          error = errors.New("wav: missing RIFF/WAVE header")
+	ErrMissingFormat    error = errors.New("wav: missing fmt chunk before data chunk")
+	ErrMissingData      error = errors.New("wav: missing data chunk")
+	ErrInvalidWhence    error = errors.New("wav: invalid whence")
+	ErrNegativePosition error = errors.New("wav: negative position")
+)
+
+// Decoder implements decoder.Decoder for RIFF/WAVE files containing integer PCM
+// (8, 16, 24, or 32 bit) or IEEE float (32 or 64 bit) samples.
+//
+// To remain compatible with the byte offsets the transformer computes, the decoder exposes
+// the audio as if it were 16 bit stereo, i.e., each frame is decoder.FrameWidth bytes wide,
+// regardless of the actual encoding of the file. Mono and multichannel files are folded to
+// stereo with decoder.Fold.
+type Decoder struct {
+	reader io.Reader
+
+	channels      int
+	bitsPerSample int
+	float         bool
+	blockAlign    int
+	sampleRate    int
+
+	// dataStart is the absolute offset of the first sample frame in the source
+	dataStart int64
+	// frames is the total number of sample frames in the data chunk, or -1 if unknown
+	frames int64
+	// pos is the index of the next sample frame to be read
+	pos int64
+
+	buf     []byte
+	scratch []float64
+}
+
+// NewDecoder parses the RIFF header of f up to the start of the data chunk and returns
+// a decoder positioned at the first sample frame.
+func NewDecoder(f io.Reader) (*Decoder, error) {
+	d := &Decoder{
+		reader: f,
+		frames: -1,
+	}
+	if err := d.parseHeader(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *Decoder) parseHeader() error {
+	var riff [12]byte
+	if _, err := io.ReadFull(d.reader, riff[:]); err != nil {
+		return ErrNotRiff
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return ErrNotRiff
+	}
+	offset := int64(len(riff))
+
+	hasFormat := false
+	for {
+		var header [8]byte
+		if _, err := io.ReadFull(d.reader, header[:]); err != nil {
+			return ErrMissingData
+		}
+		offset += int64(len(header))
+		id := string(header[0:4])
+		size := binary.LittleEndian.Uint32(header[4:8])
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return fmt.Errorf("wav: fmt chunk too small (%d bytes)", size)
+			}
+			chunk := make([]byte, size)
+			if _, err := io.ReadFull(d.reader, chunk); err != nil {
+				return err
+			}
+			offset += int64(size)
+			if err := d.parseFormat(chunk); err != nil {
+				return err
+			}
+			hasFormat = true
+		case "data":
+			if !hasFormat {
+				return ErrMissingFormat
+			}
+			d.dataStart = offset
+			if size != unknownDataSize {
+				d.frames = int64(size) / int64(d.blockAlign)
+			} else if s, ok := d.reader.(io.Seeker); ok {
+				// streaming encoders leave the size open, so derive it from the end of the source
+				end, err := s.Seek(0, io.SeekEnd)
+				if err != nil {
+					return err
+				}
+				if _, err := s.Seek(offset, io.SeekStart); err != nil {
+					return err
+				}
+				d.frames = (end - offset) / int64(d.blockAlign)
+			}
+			return nil
+		default:
+			// skip unknown chunks, which are padded to an even number of bytes
+			skip := int64(size) + int64(size%2)
+			if err := d.discard(skip); err != nil {
+				return err
+			}
+			offset += skip
+		}
+		// fmt chunks may also be padded
+		if id == "fmt " && size%2 == 1 {
+			if err := d.discard(1); err != nil {
+				return err
+			}
+			offset++
+		}
+	}
+}
+
+func (d *Decoder) parseFormat(chunk []byte) error {
+	tag := binary.LittleEndian.Uint16(chunk[0:2])
+	d.channels = int(binary.LittleEndian.Uint16(chunk[2:4]))
+	d.sampleRate = int(binary.LittleEndian.Uint32(chunk[4:8]))
+	d.blockAlign = int(binary.LittleEndian.Uint16(chunk[12:14]))
+	d.bitsPerSample = int(binary.LittleEndian.Uint16(chunk[14:16]))
+
+	if tag == formatExtensible {
+		// WAVE_FORMAT_EXTENSIBLE stores the actual format in the first two bytes of the
+		// sub-format GUID
+		if len(chunk) < 26 {
+			return errors.New("wav: extensible fmt chunk too small")
+		}
+		tag = binary.LittleEndian.Uint16(chunk[24:26])
+	}
+
+	switch tag {
+	case formatPCM:
+		switch d.bitsPerSample {
+		case 8, 16, 24, 32:
+		default:
+			return fmt.Errorf("wav: %d bit integer PCM is not supported", d.bitsPerSample)
+		}
+	case formatIEEEFloat:
+		switch d.bitsPerSample {
+		case 32, 64:
+		default:
+			return fmt.Errorf("wav: %d bit float PCM is not supported", d.bitsPerSample)
+		}
+		d.float = true
+	default:
+		return fmt.Errorf("wav: format tag 0x%04x is not supported", tag)
+	}
+
+	if d.channels == 0 {
+		return errors.New("wav: file has no channels")
+	}
+	if d.blockAlign < d.channels*d.bitsPerSample/8 {
+		return fmt.Errorf("wav: block alignment %d is too small", d.blockAlign)
+	}
+	return nil
+}
+
+// discard skips n bytes of the source, using io.Seeker if available
+func (d *Decoder) discard(n int64) error {
+	if s, ok := d.reader.(io.Seeker); ok {
+		_, err := s.Seek(n, io.SeekCurrent)
+		return err
+	}
+	_, err := io.CopyN(io.Discard, d.reader, n)
+	return err
+}
+
+// Length returns the total size in bytes, as if the file was decoded to 16 bit stereo.
+//
+// Length returns -1 when the total size is not available, i.e., when the data chunk's size is
+// left open and the source is not io.Seeker.
+func (d *Decoder) Length() int {
+	if d.frames < 0 {
+		return -1
+	}
+	return int(d.frames) * decoder.FrameWidth
+}
+
+// SampleRate returns the sample rate of the file in Hz
+func (d *Decoder) SampleRate() int {
+	return d.sampleRate
+}
+
+// Channels returns the number of channels stored in the file before folding down to stereo
+func (d *Decoder) Channels() int {
+	return d.channels
+}
+
+// Fills the samples slice with len(samples) samples.
+func (d *Decoder) Read(samples [][2]float64) (n int, err error) {
+	want := int64(len(samples))
+	if d.frames >= 0 && d.pos+want > d.frames {
+		want = d.frames - d.pos
+	}
+	if want <= 0 {
+		return 0, nil
+	}
+	size := int(want) * d.blockAlign
+	if cap(d.buf) < size {
+		d.buf = make([]byte, size)
+	}
+	buf := d.buf[:size]
+	rn, err := io.ReadFull(d.reader, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return 0, err
+	}
+	for p := buf[:rn-rn%d.blockAlign]; len(p) > 0; n++ {
+		var w int
+		samples[n], w = d.Decode(p)
+		p = p[w:]
+	}
+	d.pos += int64(n)
+	return n, nil
+}
+
+// Seek is io.Seeker's Seek.
+//
+// Offsets are interpreted as if the file was decoded to 16 bit stereo, see Length. When the
+// underlying source is not io.Seeker, only seeking forwards is supported by discarding data.
+func (d *Decoder) Seek(offset int64, whence int) (int64, error) {
+	cur := d.pos * int64(decoder.FrameWidth)
+	var npos int64
+	switch whence {
+	case io.SeekStart:
+		npos = offset
+	case io.SeekCurrent:
+		npos = cur + offset
+	case io.SeekEnd:
+		npos = int64(d.Length()) + offset
+	default:
+		return 0, ErrInvalidWhence
+	}
+	if npos < 0 {
+		return 0, ErrNegativePosition
+	}
+	frame := npos / int64(decoder.FrameWidth)
+	if d.frames >= 0 && frame > d.frames {
+		frame = d.frames
+	}
+	if frame == d.pos {
+		return npos, nil
+	}
+
+	if s, ok := d.reader.(io.Seeker); ok {
+		if _, err := s.Seek(d.dataStart+frame*int64(d.blockAlign), io.SeekStart); err != nil {
+			return 0, err
+		}
+	} else {
+		if frame < d.pos {
+			return 0, errors.New("wav: cannot seek backwards in a non-seekable source")
+		}
+		n, err := io.CopyN(io.Discard, d.reader, (frame-d.pos)*int64(d.blockAlign))
+		if err != nil {
+			d.pos += n / int64(d.blockAlign)
+			return d.pos * int64(decoder.FrameWidth), err
+		}
+	}
+	d.pos = frame
+	return npos, nil
+}
+
+// Decode converts a single frame in the file's native encoding to a pair of float64 samples
+// for the left and right channel, and returns the number of bytes consumed.
+func (d *Decoder) Decode(p []byte) (sample [2]float64, n int) {
+	width := d.bitsPerSample / 8
+	if len(d.scratch) != d.channels {
+		d.scratch = make([]float64, d.channels)
+	}
+	for c := range d.scratch {
+		d.scratch[c] = d.decodeSample(p[c*width : (c+1)*width])
+	}
+	return decoder.Fold(d.scratch), d.blockAlign
+}
+
+func (d *Decoder) decodeSample(p []byte) float64 {
+	if d.float {
+		switch d.bitsPerSample {
+		case 32:
+			return float64(math.Float32frombits(binary.LittleEndian.Uint32(p)))
+		case 64:
+			return math.Float64frombits(binary.LittleEndian.Uint64(p))
+		}
+		return 0
+	}
+	switch d.bitsPerSample {
+	case 8:
+		// 8 bit PCM is the only unsigned format
+		return float64(int(p[0])-128) / (math.Exp2(7) - 1)
+	case 16:
+		return float64(int16(binary.LittleEndian.Uint16(p))) / (math.Exp2(15) - 1)
+	case 24:
+		x := int32(uint32(p[0])<<8|uint32(p[1])<<16|uint32(p[2])<<24) >> 8
+		return float64(x) / (math.Exp2(23) - 1)
+	case 32:
+		return float64(int32(binary.LittleEndian.Uint32(p))) / (math.Exp2(31) - 1)
+	}
+	return 0
+}
+
+func (d *Decoder) Close() error {
+	return nil
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/zoomoid/waveman2/pkg/decoder"
+)
+
+// encode creates an in-memory RIFF/WAVE file from a slice of frames
+func encode(tag uint16, bits int, sampleRate int, frames [][]float64) []byte {
+	channels := len(frames[0])
+	blockAlign := channels * bits / 8
+	data := &bytes.Buffer{}
+	for _, frame := range frames {
+		for _, x := range frame {
+			switch {
+			case tag == formatIEEEFloat && bits == 32:
+				binary.Write(data, binary.LittleEndian, float32(x))
+			case tag == formatIEEEFloat && bits == 64:
+				binary.Write(data, binary.LittleEndian, x)
+			case bits == 8:
+				data.WriteByte(byte(int(math.Round(x*127)) + 128))
+			case bits == 16:
+				binary.Write(data, binary.LittleEndian, int16(math.Round(x*(math.Exp2(15)-1))))
+			case bits == 24:
+				v := int32(math.Round(x * (math.Exp2(23) - 1)))
+				data.Write([]byte{byte(v), byte(v >> 8), byte(v >> 16)})
+			case bits == 32:
+				binary.Write(data, binary.LittleEndian, int32(math.Round(x*(math.Exp2(31)-1))))
+			}
+		}
+	}
+
+	out := &bytes.Buffer{}
+	out.WriteString("RIFF")
+	binary.Write(out, binary.LittleEndian, uint32(4+8+16+8+data.Len()))
+	out.WriteString("WAVE")
+	out.WriteString("fmt ")
+	binary.Write(out, binary.LittleEndian, uint32(16))
+	binary.Write(out, binary.LittleEndian, tag)
+	binary.Write(out, binary.LittleEndian, uint16(channels))
+	binary.Write(out, binary.LittleEndian, uint32(sampleRate))
+	binary.Write(out, binary.LittleEndian, uint32(sampleRate*blockAlign))
+	binary.Write(out, binary.LittleEndian, uint16(blockAlign))
+	binary.Write(out, binary.LittleEndian, uint16(bits))
+	out.WriteString("data")
+	binary.Write(out, binary.LittleEndian, uint32(data.Len()))
+	out.Write(data.Bytes())
+	return out.Bytes()
+}
+
+func TestDecoderFormats(t *testing.T) {
+	frames := [][]float64{{0.5, -0.5}, {-0.25, 0.25}, {1, -1}, {0, 0}}
+	cases := []struct {
+		name string
+		tag  uint16
+		bits int
+		tol  float64
+	}{
+		{"pcm8", formatPCM, 8, 1.0 / 127},
+		{"pcm16", formatPCM, 16, 1e-4},
+		{"pcm24", formatPCM, 24, 1e-6},
+		{"pcm32", formatPCM, 32, 1e-8},
+		{"float32", formatIEEEFloat, 32, 1e-7},
+		{"float64", formatIEEEFloat, 64, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d, err := NewDecoder(bytes.NewReader(encode(c.tag, c.bits, 44100, frames)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.Length() != len(frames)*decoder.FrameWidth {
+				t.Fatalf("expected length %d, found %d", len(frames)*decoder.FrameWidth, d.Length())
+			}
+			samples := make([][2]float64, len(frames)+1)
+			n, err := d.Read(samples)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != len(frames) {
+				t.Fatalf("expected %d frames, found %d", len(frames), n)
+			}
+			for i, frame := range frames {
+				for ch := range samples[i] {
+					if math.Abs(samples[i][ch]-frame[ch]) > c.tol {
+						t.Errorf("frame %d channel %d: expected %g, found %g", i, ch, frame[ch], samples[i][ch])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDecoderChannels(t *testing.T) {
+	mono := encode(formatPCM, 16, 8000, [][]float64{{0.5}, {-0.5}})
+	d, err := NewDecoder(bytes.NewReader(mono))
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := make([][2]float64, 2)
+	d.Read(samples)
+	if samples[0][0] != samples[0][1] || samples[1][0] != samples[1][1] {
+		t.Errorf("expected mono to be duplicated onto both channels, found %v", samples)
+	}
+
+	quad := encode(formatIEEEFloat, 64, 8000, [][]float64{{1, 0.5, 0, -0.5}})
+	d, err = NewDecoder(bytes.NewReader(quad))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Read(samples[:1])
+	if samples[0] != [2]float64{0.5, 0} {
+		t.Errorf("expected multichannel fold-down to [0.5 0], found %v", samples[0])
+	}
+}
+
+func TestDecoderSeek(t *testing.T) {
+	frames := make([][]float64, 100)
+	for i := range frames {
+		frames[i] = []float64{float64(i) / 100, 0}
+	}
+	raw := encode(formatIEEEFloat, 64, 8000, frames)
+
+	// seekable source
+	d, err := NewDecoder(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Seek(int64(50*decoder.FrameWidth), io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	samples := make([][2]float64, 1)
+	d.Read(samples)
+	if samples[0][0] != 0.5 {
+		t.Errorf("expected 0.5 after seeking to frame 50, found %g", samples[0][0])
+	}
+	if _, err := d.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	d.Read(samples)
+	if samples[0][0] != 0 {
+		t.Errorf("expected 0 after seeking to the start, found %g", samples[0][0])
+	}
+
+	// non-seekable source only supports seeking forwards
+	d, err = NewDecoder(io.MultiReader(bytes.NewReader(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Seek(int64(10*decoder.FrameWidth), io.SeekCurrent); err != nil {
+		t.Fatal(err)
+	}
+	d.Read(samples)
+	if samples[0][0] != 0.1 {
+		t.Errorf("expected 0.1 after seeking to frame 10, found %g", samples[0][0])
+	}
+	if _, err := d.Seek(0, io.SeekStart); err == nil {
+		t.Error("expected error when seeking backwards in a non-seekable source")
+	}
+}
+
+func TestNotRiff(t *testing.T) {
+	_, err := NewDecoder(bytes.NewReader([]byte("ID3\x04\x00\x00\x00\x00\x00\x00\x00\x00")))
+	if err != ErrNotRiff {
+		t.Errorf("expected ErrNotRiff, found %v", err)
+	}
+}
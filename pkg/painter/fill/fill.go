@@ -0,0 +1,317 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fill implements paints for SVG elements beyond flat colors, namely linear and radial
+// gradients spanning the entire canvas and color stops mapping sample values to colors.
+//
+// Fills are given as CSS-like specifications:
+//
+//	black                                    a flat color, any CSS-compliant value
+//	linear-gradient(90deg, #f00, #00f 80%)   a linear gradient across the canvas
+//	radial-gradient(#fff, #000)              a radial gradient from the canvas's center
+//	stops(#0f0, #ff0 60%, #f00)              a color mapped from each sample value in [0,1]
+//
+// Linear gradient angles follow CSS, i.e., 0deg points upwards, 90deg to the right, and
+// 180deg, the default, downwards. The direction can also be given as "to top", "to right",
+// "to bottom", or "to left". A vertical gradient "to top" thus colors boxes by their height.
+// Stops without an offset are distributed evenly between their neighbours.
+package fill
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Kind is the categorical type of a fill
+type Kind string
+
+const (
+	// KindSolid is a flat color
+	KindSolid Kind = "solid"
+	// KindLinear is a linear gradient across the canvas
+	KindLinear Kind = "linear-gradient"
+	// KindRadial is a radial gradient from the canvas's center to its corners
+	KindRadial Kind = "radial-gradient"
+	// KindStops maps each sample value to a color interpolated between the stops
+	KindStops Kind = "stops"
+)
+
+var (
+	ErrMalformed error = errors.New("fill specification is malformed")
+	ErrNoStops   error = errors.New("fill requires at least two color stops")
+)
+
+// Stop is a color at an offset in [0,1] along a gradient or the range of sample values
+type Stop struct {
+	Offset float64
+	Color  string
+}
+
+// Fill is a paint for SVG elements
+type Fill struct {
+	Kind Kind
+	// Color is the flat color of solid fills
+	Color string
+	// Stops are the color stops of gradients and value mappings, ordered by offset
+	Stops []Stop
+	// Angle is the direction of linear gradients in degrees, see the package documentation
+	Angle float64
+	// ID references the gradient element of linear and radial gradients
+	ID string
+}
+
+// Parse parses a fill specification, see the package documentation. Specifications that are
+// not of a gradient or stops kind are flat colors and returned as is.
+func Parse(id string, spec string) (*Fill, error) {
+	spec = strings.TrimSpace(spec)
+	kind, args, ok := function(spec)
+	if !ok {
+		return &Fill{Kind: KindSolid, Color: spec}, nil
+	}
+	f := &Fill{Kind: kind, ID: id, Angle: 180}
+	if kind == KindLinear && len(args) > 0 {
+		if angle, ok := parseAngle(args[0]); ok {
+			f.Angle = angle
+			args = args[1:]
+		}
+	}
+	stops, err := parseStops(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", spec, err)
+	}
+	f.Stops = stops
+	if kind == KindStops {
+		for _, stop := range stops {
+			if _, err := ParseHexColor(stop.Color); err != nil {
+				return nil, fmt.Errorf("%s: stops require hex colors: %w", spec, err)
+			}
+		}
+	}
+	return f, nil
+}
+
+// Validate checks a fill specification to be well-formed
+func Validate(spec string) error {
+	_, err := Parse("", spec)
+	return err
+}
+
+// MustParse parses a fill specification like Parse, but falls back to a flat color of the
+// specification itself if it is malformed
+func MustParse(id string, spec string) *Fill {
+	f, err := Parse(id, spec)
+	if err != nil {
+		return &Fill{Kind: KindSolid, Color: spec}
+	}
+	return f
+}
+
+// Paint returns the value of an SVG paint attribute such as fill or stroke for an element
+// representing the given sample value. Gradients reference their definition, see Fill.Def.
+func (f *Fill) Paint(value float64) string {
+	switch f.Kind {
+	case KindLinear, KindRadial:
+		return fmt.Sprintf("url(#%s)", f.ID)
+	case KindStops:
+		return f.interpolate(value)
+	}
+	return f.Color
+}
+
+// Def returns the SVG definition of gradients spanning a canvas of the given size, and an
+// empty string for all other fills
+func (f *Fill) Def(width float64, height float64) string {
+	stops := &strings.Builder{}
+	for _, stop := range f.Stops {
+		fmt.Fprintf(stops, `<stop offset="%g" stop-color="%s" />`, stop.Offset, stop.Color)
+	}
+	switch f.Kind {
+	case KindLinear:
+		// the gradient line passes through the canvas's center and is just long enough for
+		// the corners to be at its ends, like in CSS
+		rad := f.Angle * math.Pi / 180
+		// round the direction to avoid floating point noise for axis-aligned gradients
+		dx, dy := math.Round(math.Sin(rad)*1e9)/1e9, math.Round(-math.Cos(rad)*1e9)/1e9
+		l := 0.5 * (math.Abs(width*dx) + math.Abs(height*dy))
+		cx, cy := 0.5*width, 0.5*height
+		return fmt.Sprintf(`<linearGradient id="%s" gradientUnits="userSpaceOnUse" x1="%g" y1="%g" x2="%g" y2="%g">%s</linearGradient>`,
+			f.ID, cx-dx*l, cy-dy*l, cx+dx*l, cy+dy*l, stops.String())
+	case KindRadial:
+		return fmt.Sprintf(`<radialGradient id="%s" gradientUnits="userSpaceOnUse" cx="%g" cy="%g" r="%g">%s</radialGradient>`,
+			f.ID, 0.5*width, 0.5*height, 0.5*math.Hypot(width, height), stops.String())
+	}
+	return ""
+}
+
+// interpolate mixes the colors of the stops adjacent to value linearly
+func (f *Fill) interpolate(value float64) string {
+	if len(f.Stops) == 0 {
+		return f.Color
+	}
+	if value <= f.Stops[0].Offset {
+		return f.Stops[0].Color
+	}
+	for i := 1; i < len(f.Stops); i++ {
+		lower, upper := f.Stops[i-1], f.Stops[i]
+		if value > upper.Offset {
+			continue
+		}
+		t := 0.0
+		if upper.Offset > lower.Offset {
+			t = (value - lower.Offset) / (upper.Offset - lower.Offset)
+		}
+		a, _ := ParseHexColor(lower.Color)
+		b, _ := ParseHexColor(upper.Color)
+		var mixed [3]int
+		for c := range mixed {
+			mixed[c] = int(math.Round(a[c] + t*(b[c]-a[c])))
+		}
+		return fmt.Sprintf("#%02x%02x%02x", mixed[0], mixed[1], mixed[2])
+	}
+	return f.Stops[len(f.Stops)-1].Color
+}
+
+// function splits a specification of the form kind(arg, ...) into its kind and arguments
+func function(spec string) (Kind, []string, bool) {
+	open := strings.Index(spec, "(")
+	if open < 0 || !strings.HasSuffix(spec, ")") {
+		return "", nil, false
+	}
+	kind := Kind(strings.TrimSpace(spec[:open]))
+	switch kind {
+	case KindLinear, KindRadial, KindStops:
+	default:
+		return "", nil, false
+	}
+	return kind, splitArguments(spec[open+1 : len(spec)-1]), true
+}
+
+// splitArguments splits a comma-separated list at the top level, such that colors like
+// rgb(0, 0, 0) remain intact
+func splitArguments(s string) []string {
+	var args []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(args, strings.TrimSpace(s[start:]))
+}
+
+// parseAngle parses a linear gradient's direction as either an angle in degrees or a keyword
+func parseAngle(arg string) (float64, bool) {
+	switch arg {
+	case "to top":
+		return 0, true
+	case "to right":
+		return 90, true
+	case "to bottom":
+		return 180, true
+	case "to left":
+		return 270, true
+	}
+	if !strings.HasSuffix(arg, "deg") {
+		return 0, false
+	}
+	angle, err := strconv.ParseFloat(strings.TrimSuffix(arg, "deg"), 64)
+	return angle, err == nil
+}
+
+// parseStops parses color stops of the form "color [offset%]" and distributes stops without
+// an offset evenly between their neighbours
+func parseStops(args []string) ([]Stop, error) {
+	if len(args) < 2 {
+		return nil, ErrNoStops
+	}
+	stops := make([]Stop, len(args))
+	known := make([]bool, len(args))
+	for i, arg := range args {
+		color, offset := arg, ""
+		// the offset is separated from the color by the last space outside of parentheses
+		if j := strings.LastIndex(arg, " "); j > strings.LastIndex(arg, ")") {
+			color, offset = strings.TrimSpace(arg[:j]), arg[j+1:]
+		}
+		if color == "" {
+			return nil, ErrMalformed
+		}
+		stops[i].Color = color
+		if offset == "" {
+			continue
+		}
+		if !strings.HasSuffix(offset, "%") {
+			return nil, fmt.Errorf("stop offset %s is not a percentage: %w", offset, ErrMalformed)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSuffix(offset, "%"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("stop offset %s is not a percentage: %w", offset, ErrMalformed)
+		}
+		stops[i].Offset = math.Max(0, math.Min(1, v/100))
+		known[i] = true
+	}
+	if !known[0] {
+		stops[0].Offset, known[0] = 0, true
+	}
+	if n := len(stops) - 1; !known[n] {
+		stops[n].Offset, known[n] = 1, true
+	}
+	for i := 1; i < len(stops); i++ {
+		if known[i] {
+			// offsets never decrease, like in CSS
+			stops[i].Offset = math.Max(stops[i].Offset, stops[i-1].Offset)
+			continue
+		}
+		next := i + 1
+		for !known[next] {
+			next++
+		}
+		step := (stops[next].Offset - stops[i-1].Offset) / float64(next-i+1)
+		stops[i].Offset, known[i] = stops[i-1].Offset+step, true
+	}
+	return stops, nil
+}
+
+// ParseHexColor parses a CSS hex color in the form #rrggbb or #rgb to its red, green, and blue
+// components
+func ParseHexColor(color string) ([3]float64, error) {
+	rgb := [3]float64{}
+	hex := strings.TrimPrefix(color, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if !strings.HasPrefix(color, "#") || len(hex) != 6 {
+		return rgb, fmt.Errorf("color %s is not a hex color", color)
+	}
+	for c := range rgb {
+		v, err := strconv.ParseUint(hex[2*c:2*c+2], 16, 8)
+		if err != nil {
+			return rgb, fmt.Errorf("color %s is not a hex color", color)
+		}
+		rgb[c] = float64(v)
+	}
+	return rgb, nil
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fill
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("solid", func(t *testing.T) {
+		f, err := Parse("id", "rgba(0 0 0 / 0.5)")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Kind != KindSolid || f.Paint(0.5) != "rgba(0 0 0 / 0.5)" {
+			t.Errorf("expected solid fill to paint its color, found %v", f)
+		}
+		if f.Def(100, 100) != "" {
+			t.Errorf("expected no definition for solid fill")
+		}
+	})
+
+	t.Run("linear", func(t *testing.T) {
+		f, err := Parse("id", "linear-gradient(to right, rgb(255, 0, 0), #00f)")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Angle != 90 {
+			t.Errorf("expected angle 90, found %f", f.Angle)
+		}
+		expected := []Stop{{0, "rgb(255, 0, 0)"}, {1, "#00f"}}
+		for i, stop := range expected {
+			if f.Stops[i] != stop {
+				t.Errorf("expected stop %v, found %v", stop, f.Stops[i])
+			}
+		}
+		if f.Paint(0) != "url(#id)" {
+			t.Errorf("expected gradient reference, found %s", f.Paint(0))
+		}
+		def := f.Def(200, 100)
+		if !strings.Contains(def, `x1="0" y1="50" x2="200" y2="50"`) {
+			t.Errorf("expected horizontal gradient line across the canvas, found %s", def)
+		}
+	})
+
+	t.Run("stop offsets", func(t *testing.T) {
+		f, err := Parse("id", "radial-gradient(#000, #111, #222 80%, #333)")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []float64{0, 0.4, 0.8, 1}
+		for i, offset := range expected {
+			if f.Stops[i].Offset != offset {
+				t.Errorf("expected offset %f of stop %d, found %f", offset, i, f.Stops[i].Offset)
+			}
+		}
+	})
+
+	t.Run("stops", func(t *testing.T) {
+		f, err := Parse("id", "stops(#000000, #ff0000 50%, #ffffff)")
+		if err != nil {
+			t.Fatal(err)
+		}
+		cases := map[float64]string{
+			-1:   "#000000",
+			0.25: "#800000",
+			0.5:  "#ff0000",
+			0.75: "#ff8080",
+			2:    "#ffffff",
+		}
+		for value, color := range cases {
+			if c := f.Paint(value); c != color {
+				t.Errorf("expected %s for %f, found %s", color, value, c)
+			}
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		if _, err := Parse("id", "linear-gradient(#fff)"); !errors.Is(err, ErrNoStops) {
+			t.Errorf("expected ErrNoStops, found %v", err)
+		}
+		if _, err := Parse("id", "stops(red, blue)"); err == nil {
+			t.Errorf("expected error for stops without hex colors")
+		}
+		if _, err := Parse("id", "linear-gradient(#fff 1/2, #000)"); !errors.Is(err, ErrMalformed) {
+			t.Errorf("expected ErrMalformed, found %v", err)
+		}
+	})
+}
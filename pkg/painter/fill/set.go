@@ -0,0 +1,65 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fill
+
+import (
+	"fmt"
+	"math"
+)
+
+// Set collects the fills of a painter and assigns unique IDs to their gradients, such that
+// their definitions can be emitted once in the SVG's <defs>
+type Set struct {
+	prefix string
+	fills  []*Fill
+}
+
+// NewSet creates an empty set whose gradient IDs start with prefix, e.g., the painter's name
+func NewSet(prefix string) *Set {
+	return &Set{prefix: prefix}
+}
+
+// Add parses a fill specification and adds it to the set. Malformed specifications are
+// treated as flat colors, see MustParse.
+func (s *Set) Add(spec string) *Fill {
+	f := MustParse(fmt.Sprintf("%s-fill-%d", s.prefix, len(s.fills)), spec)
+	s.fills = append(s.fills, f)
+	return f
+}
+
+// Defs returns the definitions of all gradients in the set for a canvas of the given size
+func (s *Set) Defs(width float64, height float64) []string {
+	var defs []string
+	for _, f := range s.fills {
+		if def := f.Def(width, height); def != "" {
+			defs = append(defs, def)
+		}
+	}
+	return defs
+}
+
+// Max returns the maximum of all samples, which painters drawing a single shape for all samples
+// map to a color with color stops
+func Max(samples ...[]float64) float64 {
+	var max float64
+	for _, series := range samples {
+		for _, sample := range series {
+			max = math.Max(max, sample)
+		}
+	}
+	return max
+}
@@ -16,11 +16,55 @@ limitations under the License.
 
 package painter
 
+import "github.com/zoomoid/waveman2/pkg/transform"
+
 type PainterOptions struct {
 	// Data contains all sample points to use in a drawing context
-	Data   []float64
-	Height float64
-	Width  float64
+	Data []float64
+	// LowerData contains an optional second series of sample points of the same length as
+	// Data, e.g., the right channel of a split stereo transformation. Painters supporting it
+	// draw Data in the upper and LowerData mirrored in the lower half of the canvas.
+	LowerData []float64
+	// LowerEnvelope contains the signed lower envelope of the signal when Data contains the
+	// signed upper envelope, e.g., from the min-max aggregator, both in [-1,1]. Painters
+	// supporting envelopes draw them independently around the canvas's horizontal center axis.
+	LowerEnvelope []float64
+	// Layers contains additional series of sample points ordered from back to front, e.g., a
+	// peak envelope behind RMS blocks. Painters supporting layers draw them instead of Data.
+	Layers []Layer
+	// Bands contains the energy of the low, mid, and high frequency band of each sample point,
+	// if the transformer analyzed them. Painters supporting bands color elements by them.
+	Bands []transform.Bands
+	// Spectrogram contains the spectrum of each sample point, if the transformer computed it
+	Spectrogram *transform.Spectrogram
+	Height      float64
+	Width       float64
+	// Metadata describes the audio source of Data, e.g., for labeling or timing elements.
+	// May be nil when the data does not originate from a transformer.
+	Metadata *transform.Metadata
+	// ID prefixes the IDs of all definitions of a painter, e.g., gradients. IDs must be unique
+	// across a document, so SVGs inlined into the same HTML page require distinct IDs.
+	ID string
+}
+
+// IDPrefix returns the prefix of the IDs of a painter's definitions, which is the painter's
+// name, preceded by ID if set
+func (o *PainterOptions) IDPrefix(name string) string {
+	if o.ID == "" {
+		return name
+	}
+	return o.ID + "-" + name
+}
+
+// Layer is a named series of sample points drawn stacked with other layers
+type Layer struct {
+	// Name identifies the layer, e.g., by the aggregator its data was computed with
+	Name string
+	// Data contains the sample points of the layer
+	Data []float64
+	// LowerData contains the sample points drawn in the lower half of the canvas, see
+	// PainterOptions.LowerData
+	LowerData []float64
 }
 
 // Painter is the interface each plugin's backend has to implement. It converts samples into SVG elements.
@@ -35,6 +79,29 @@ type Painter interface {
 	Viewbox() string
 }
 
+// DefsPainter is implemented by painters whose elements reference SVG definitions, e.g.,
+// gradients, which are to be written to the SVG's <defs>
+type DefsPainter interface {
+	// Defs returns the definitions referenced by the elements of the last call to Draw
+	Defs() []string
+}
+
+// Defs returns the definitions of a painter if it implements DefsPainter, and nil otherwise
+func Defs(p Painter) []string {
+	if d, ok := p.(DefsPainter); ok {
+		return d.Defs()
+	}
+	return nil
+}
+
+// AxisPainter is implemented by painters whose shapes grow vertically from a horizontal axis,
+// e.g., boxes aligned to the canvas's bottom. Shapes of such painters can be animated from
+// flat at the axis to their final shape.
+type AxisPainter interface {
+	// Axis returns the vertical position of the axis on the canvas
+	Axis() float64
+}
+
 const (
 	DefaultWidth  float64 = 10
 	DefaultHeight float64 = 200
@@ -0,0 +1,159 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package peaks implements the JSON and binary data formats of BBC's audiowaveform, which
+// waveform players such as wavesurfer.js or peaks.js read to render waveforms client-side.
+//
+// Both formats contain a pair of a minimum and a maximum per pixel, i.e., per chunk, and
+// channel, as signed integers of either 8 or 16 bits. Pairs of all channels are interleaved
+// per pixel. See https://github.com/bbc/audiowaveform/blob/master/doc/DataFormat.md.
+package peaks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/zoomoid/waveman2/pkg/transform"
+)
+
+const (
+	// Version is the version of the data format, which is the first to support multiple
+	// channels
+	Version int = 2
+	// DefaultBits is the default resolution of data points, which is audiowaveform's default
+	DefaultBits int = 16
+)
+
+var (
+	ErrBits   error = errors.New("peaks support only 8 or 16 bits")
+	ErrLength error = errors.New("channels differ in length")
+)
+
+// Channel contains the envelope of a single channel. Each value is in [-1,1].
+type Channel struct {
+	// Max is the upper envelope of the channel, e.g., transform.ReaderContext.Blocks()
+	Max []float64
+	// Min is the lower envelope of the channel, e.g., transform.ReaderContext.LowerEnvelope().
+	// When nil, Max is mirrored at zero.
+	Min []float64
+}
+
+// Peaks is the waveform data in the format of audiowaveform
+type Peaks struct {
+	Version         int   `json:"version"`
+	Channels        int   `json:"channels"`
+	SampleRate      int   `json:"sample_rate"`
+	SamplesPerPixel int   `json:"samples_per_pixel"`
+	Bits            int   `json:"bits"`
+	Length          int   `json:"length"`
+	Data            []int `json:"data"`
+}
+
+// New quantizes the envelopes of channels to the given number of bits. Sample rate and samples
+// per pixel are taken from the metadata of the transformer producing the envelopes.
+func New(metadata *transform.Metadata, bits int, channels ...Channel) (*Peaks, error) {
+	if bits != 8 && bits != 16 {
+		return nil, ErrBits
+	}
+	length := 0
+	if len(channels) > 0 {
+		length = len(channels[0].Max)
+	}
+	for _, c := range channels {
+		if len(c.Max) != length || (c.Min != nil && len(c.Min) != length) {
+			return nil, ErrLength
+		}
+	}
+	p := &Peaks{
+		Version:  Version,
+		Channels: len(channels),
+		Bits:     bits,
+		Length:   length,
+		Data:     make([]int, 0, 2*length*len(channels)),
+	}
+	if metadata != nil {
+		p.SampleRate = metadata.SampleRate
+		p.SamplesPerPixel = metadata.SamplesPerChunk
+	}
+	scale := float64(int(1)<<(bits-1) - 1)
+	quantize := func(v float64) int {
+		return int(math.Round(math.Max(-1, math.Min(1, v)) * scale))
+	}
+	for i := 0; i < length; i++ {
+		for _, c := range channels {
+			max := quantize(c.Max[i])
+			min := -max
+			if c.Min != nil {
+				min = quantize(c.Min[i])
+			}
+			p.Data = append(p.Data, min, max)
+		}
+	}
+	return p, nil
+}
+
+// JSON encodes the peaks in audiowaveform's JSON format
+func (p *Peaks) JSON() (*bytes.Buffer, error) {
+	out := &bytes.Buffer{}
+	if err := json.NewEncoder(out).Encode(p); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Dat encodes the peaks in audiowaveform's binary format, i.e., a header of little-endian
+// 32 bit integers followed by the data points as 8 or 16 bit little-endian integers
+func (p *Peaks) Dat() (*bytes.Buffer, error) {
+	var flags uint32
+	switch p.Bits {
+	case 8:
+		// the lowest bit of the flags denotes 8 bit data
+		flags = 1
+	case 16:
+	default:
+		return nil, ErrBits
+	}
+	header := []interface{}{
+		int32(p.Version),
+		flags,
+		int32(p.SampleRate),
+		int32(p.SamplesPerPixel),
+		uint32(p.Length),
+		int32(p.Channels),
+	}
+	out := &bytes.Buffer{}
+	for _, field := range header {
+		if err := binary.Write(out, binary.LittleEndian, field); err != nil {
+			return nil, fmt.Errorf("failed to write header: %w", err)
+		}
+	}
+	for _, v := range p.Data {
+		var err error
+		if p.Bits == 8 {
+			err = binary.Write(out, binary.LittleEndian, int8(v))
+		} else {
+			err = binary.Write(out, binary.LittleEndian, int16(v))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to write data: %w", err)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peaks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zoomoid/waveman2/pkg/transform"
+)
+
+func TestNew(t *testing.T) {
+	metadata := &transform.Metadata{SampleRate: 44100, SamplesPerChunk: 512}
+	t.Run("mirrored", func(t *testing.T) {
+		p, err := New(metadata, 8, Channel{Max: []float64{0, 0.5, 2}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []int{0, 0, -64, 64, -127, 127}
+		if !reflect.DeepEqual(p.Data, expected) {
+			t.Errorf("expected %v, found %v", expected, p.Data)
+		}
+		if p.Length != 3 || p.Channels != 1 || p.SampleRate != 44100 || p.SamplesPerPixel != 512 {
+			t.Errorf("unexpected header %+v", p)
+		}
+	})
+
+	t.Run("interleaved", func(t *testing.T) {
+		p, err := New(metadata, 16,
+			Channel{Max: []float64{1, 0.5}, Min: []float64{-0.5, 0.25}},
+			Channel{Max: []float64{0, 1}},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []int{-16384, 32767, 0, 0, 8192, 16384, -32767, 32767}
+		if !reflect.DeepEqual(p.Data, expected) {
+			t.Errorf("expected %v, found %v", expected, p.Data)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := New(metadata, 12, Channel{Max: []float64{0}}); !errors.Is(err, ErrBits) {
+			t.Errorf("expected ErrBits, found %v", err)
+		}
+		if _, err := New(metadata, 8, Channel{Max: []float64{0}}, Channel{Max: []float64{0, 1}}); !errors.Is(err, ErrLength) {
+			t.Errorf("expected ErrLength, found %v", err)
+		}
+	})
+}
+
+func TestEncode(t *testing.T) {
+	p, err := New(&transform.Metadata{SampleRate: 48000, SamplesPerChunk: 256}, 8, Channel{Max: []float64{1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := p.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"version":2,"channels":1,"sample_rate":48000,"samples_per_pixel":256,"bits":8,"length":1,"data":[-127,127]}`
+	if strings.TrimSpace(out.String()) != expected {
+		t.Errorf("expected %s, found %s", expected, out.String())
+	}
+
+	out, err = p.Dat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := make([]int32, 6)
+	if err := binary.Read(bytes.NewReader(out.Bytes()[:24]), binary.LittleEndian, header); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(header, []int32{2, 1, 48000, 256, 1, 1}) {
+		t.Errorf("unexpected header %v", header)
+	}
+	if data := out.Bytes()[24:]; !bytes.Equal(data, []byte{0x81, 0x7f}) {
+		t.Errorf("unexpected data %v", data)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/zoomoid/waveman2/pkg/painter"
+	"github.com/zoomoid/waveman2/pkg/transform"
 )
 
 type Plugin interface {
@@ -47,6 +48,13 @@ type Plugin interface {
 	Painter() painter.Painter
 }
 
+// TransformerConfigurer is an optional interface for plugins that require additional data from
+// the transformer stage, e.g., a spectrogram. ConfigureTransformer is called with the options
+// derived from the transformer flags before each file is transformed, and may modify them.
+type TransformerConfigurer interface {
+	ConfigureTransformer(options *transform.ReaderOptions)
+}
+
 // FlagsFactory is a function type that, upon calling from the Plugin function, should register all flags the
 // Plugin painter requires to the FlagSet of the command. Data is arbitrary data, that maps to the flag values.
 type FlagsFactory func(flags *pflag.FlagSet, data interface{})
@@ -18,7 +18,10 @@ package v1
 
 import (
 	"github.com/zoomoid/waveman2/pkg/plugins/core/v1/box"
+	"github.com/zoomoid/waveman2/pkg/plugins/core/v1/dots"
 	"github.com/zoomoid/waveman2/pkg/plugins/core/v1/line"
+	"github.com/zoomoid/waveman2/pkg/plugins/core/v1/radial"
+	"github.com/zoomoid/waveman2/pkg/plugins/core/v1/spectrogram"
 	"github.com/zoomoid/waveman2/pkg/plugins/core/v1/sweep"
 	"github.com/zoomoid/waveman2/pkg/plugins/core/v1/wave"
 )
@@ -38,3 +41,15 @@ var NewWavePainter = wave.NewPainter
 var Sweep = sweep.Plugin
 
 var NewSweepPainter = sweep.NewPainter
+
+var Spectrogram = spectrogram.Plugin
+
+var NewSpectrogramPainter = spectrogram.NewPainter
+
+var Radial = radial.Plugin
+
+var NewRadialPainter = radial.NewPainter
+
+var Dots = dots.Plugin
+
+var NewDotsPainter = dots.NewPainter
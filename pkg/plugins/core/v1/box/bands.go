@@ -0,0 +1,49 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package box
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/zoomoid/waveman2/pkg/painter/fill"
+	"github.com/zoomoid/waveman2/pkg/transform"
+)
+
+// mixBands mixes the band colors weighted by the energy of each band relative to the dominant
+// band. Returns false when the bands contain no energy or a color cannot be parsed.
+func mixBands(bands transform.Bands, colors []string) (string, bool) {
+	energies := []float64{bands.Low, bands.Mid, bands.High}
+	dominant := math.Max(bands.Low, math.Max(bands.Mid, bands.High))
+	if dominant <= 0 || len(colors) < len(energies) {
+		return "", false
+	}
+	var mixed [3]float64
+	for i, energy := range energies {
+		rgb, err := fill.ParseHexColor(colors[i])
+		if err != nil {
+			return "", false
+		}
+		for c := range mixed {
+			mixed[c] += rgb[c] * energy / dominant
+		}
+	}
+	for c := range mixed {
+		mixed[c] = math.Min(255, math.Round(mixed[c]))
+	}
+	return fmt.Sprintf("#%02x%02x%02x", int(mixed[0]), int(mixed[1]), int(mixed[2])), true
+}
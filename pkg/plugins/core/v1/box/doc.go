@@ -24,7 +24,11 @@ var (
 	description string = dedent.Dedent(`
 		The box painter draws a simple box for each data point.
 
-		The box color can be set with --color.
+		The box color can be set with --color. When the transformer analyzes frequency
+		bands with --bands, each box is colored by mixing the hex colors given with
+		--band-colors for the low, mid, and high band by their energy instead.
+		--color and --layer-colors also accept gradients and color stops, e.g.,
+		"stops(#0a0, #f00)" to color each box by its height.
 		
 		The alignment axis can be either "top", "center", or "bottom",
 		and set with --alignment. 
@@ -45,5 +49,14 @@ var (
 		Notably, rounding requires the boxes to have a minimum height, namely at least
 		the width of the box, to look aesthetically pleasing. When using --rounded,
 		each box's height will have its width as a lower bound.
+
+		--reflection adds a translucent reflection below each box, SoundCloud-style. Its
+		value is the height of the reflection relative to the box, e.g., 0.5 for a
+		reflection half as high as the box. Boxes and reflections share the canvas's 
+		height, and alignment does not apply. When the transformer produces separate
+		lower data, e.g., with --channels split-stereo, reflections show the lower data instead of 
+		mirroring the boxes. --reflection-gap sets the space between boxes and their
+		reflections, and --reflection-color and --reflection-opacity set the reflections'
+		fill.
 	`)
 )
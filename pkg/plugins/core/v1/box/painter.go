@@ -18,10 +18,12 @@ package box
 
 import (
 	"fmt"
+	"math"
 	"strings"
 	"text/template"
 
 	"github.com/zoomoid/waveman2/pkg/painter"
+	"github.com/zoomoid/waveman2/pkg/painter/fill"
 )
 
 // Alignment is the categorical type for determining the box alignment axis
@@ -41,7 +43,8 @@ const (
 var Alignments = []string{"center", "top", "bottom"}
 
 const (
-	DefaultRectangleTemplate = `<rect width="{{.Width}}" height="{{.Height}}" x="{{.X}}" y="{{.Y}}" rx="{{.Rounded}}" ry="{{.Rounded}}" fill="{{.Color}}" />`
+	DefaultRectangleTemplate  = `<rect width="{{.Width}}" height="{{.Height}}" x="{{.X}}" y="{{.Y}}" rx="{{.Rounded}}" ry="{{.Rounded}}" fill="{{.Color}}" />`
+	DefaultReflectionTemplate = `<rect width="{{.Width}}" height="{{.Height}}" x="{{.X}}" y="{{.Y}}" rx="{{.Rounded}}" ry="{{.Rounded}}" fill="{{.Color}}" fill-opacity="{{.Opacity}}" />`
 )
 
 const (
@@ -59,15 +62,38 @@ const (
 	DefaultGap = float64(5)
 	// DefaultRounded rounding ratio is 10px
 	DefaultRounded = float64(10)
+	// DefaultLayerColor is the color of all but the topmost layer when no color is given
+	// for them
+	DefaultLayerColor = "rgba(0 0 0 / 0.3)"
+	// DefaultReflection ratio is 0, which disables reflections
+	DefaultReflection = float64(0)
+	// DefaultReflectionGap between boxes and their reflections is 2px
+	DefaultReflectionGap = float64(2)
+	// DefaultReflectionOpacity of reflections is 0.5
+	DefaultReflectionOpacity = float64(0.5)
 )
 
+// DefaultBandColors are the colors of the low, mid, and high frequency band, i.e., bass is
+// red, mids are green, and highs are blue
+var DefaultBandColors = []string{"#ff0000", "#00ff00", "#0000ff"}
+
 // Compile-time type checking for BoxPainter to implement all functions required
 // by the Painter interface
 var _ painter.Painter = &BoxPainter{}
 
 type BoxOptions struct {
-	// Color for each rectangle, in a CSS-compliant format
+	// Color for each rectangle, in a CSS-compliant format or as a fill specification, see
+	// package fill. Color stops map each box's sample to its color.
 	Color string
+	// LayerColors are the colors of each of the painter's layers, ordered from back to
+	// front. Layers without a color use Color for the topmost layer and DefaultLayerColor
+	// for all others
+	LayerColors []string
+	// BandColors are the colors of the low, mid, and high frequency band as hex triplets. When
+	// the painter's data contains bands, boxes are colored by mixing the band colors weighted
+	// by the ratio of each band's energy, such that the dominant band's color is at full
+	// intensity. Applies to the topmost layer only.
+	BandColors []string
 	// Alignment of the boxes, either top, center, or bottom
 	Alignment Alignment
 	// BoxHeight is the factor by which each sample value gets scaled upwards. Since
@@ -85,6 +111,19 @@ type BoxOptions struct {
 	// in the bounding box of the height and the width, with their inner width
 	// being reduced by the gap.
 	Gap float64
+	// Reflection is the height of the reflection below each box relative to the box's
+	// height. When greater than 0, boxes grow upwards from an axis above the canvas's bottom,
+	// and their reflections grow downwards from below the axis, such that both fit the
+	// canvas's height. Reflections show the lower data if given, and mirror the data
+	// otherwise. Alignment does not apply.
+	Reflection float64
+	// ReflectionGap is the vertical spacing between the boxes and their reflections
+	ReflectionGap float64
+	// ReflectionColor is the color of all reflections. Reflections use the color of their
+	// box if empty
+	ReflectionColor string
+	// ReflectionOpacity is the fill opacity of all reflections
+	ReflectionOpacity float64
 	// totalWidth is the canvas's width that results from adding up each box's
 	// width
 	totalWidth float64
@@ -100,6 +139,8 @@ type BoxPainter struct {
 	*painter.PainterOptions
 	// Embed all options for the box drawer
 	*BoxOptions
+	// fills collects the fills of the last call to Draw
+	fills *fill.Set
 }
 
 // Height returns the canvas's total height. When normalized samples are
@@ -129,6 +170,9 @@ func NewPainter(painter *painter.PainterOptions, options *BoxOptions) *BoxPainte
 	if options.BoxWidth == 0 {
 		options.BoxWidth = DefaultWidth
 	}
+	if len(options.BandColors) == 0 {
+		options.BandColors = DefaultBandColors
+	}
 
 	options.totalHeight = options.BoxHeight
 	options.totalWidth = options.BoxWidth * float64(len(painter.Data))
@@ -147,16 +191,149 @@ func (o *BoxPainter) Draw() []string {
 	rectTemplate := template.New("rect")
 	rectTemplate.Parse(DefaultRectangleTemplate)
 
+	o.fills = fill.NewSet(o.IDPrefix("box"))
 	output.WriteString("<g>")
-	for index, sample := range o.Data {
+	if len(o.Layers) == 0 {
+		o.drawSeries(output, rectTemplate, o.Data, o.LowerData, o.colors(o.fills.Add(o.Color), o.Data, true))
+	}
+	for index, layer := range o.Layers {
+		top := index == len(o.Layers)-1
+		o.drawSeries(output, rectTemplate, layer.Data, layer.LowerData, o.colors(o.fills.Add(o.layerColor(index)), layer.Data, top))
+	}
+	output.WriteString("</g>")
+	return []string{output.String()}
+}
+
+// drawSeries draws a box for each sample of data in its color, or two boxes when lower data
+// is given for split rendering
+func (o *BoxPainter) drawSeries(output *strings.Builder, rectTemplate *template.Template, data []float64, lower []float64, color func(index int) string) {
+	if o.Reflection > 0 {
+		o.drawReflected(output, data, lower, color)
+		return
+	}
+	if lower != nil {
+		o.drawSplit(output, rectTemplate, data, lower, color)
+		return
+	}
+	for index, sample := range data {
 		if sample*o.BoxHeight < o.BoxWidth {
 			sample = (o.BoxWidth - o.Gap) / o.BoxHeight
 		}
 		rect := o.perSample(index, sample)
+		rect.Color = color(index)
 		rectTemplate.Execute(output, rect)
 	}
-	output.WriteString("</g>")
-	return []string{output.String()}
+}
+
+// colors returns the color of each box of a series, which is the band color of the sample
+// when spectral coloring applies to the series and the sample's bands contain energy, and
+// the series' paint for the sample otherwise
+func (o *BoxPainter) colors(paint *fill.Fill, data []float64, spectral bool) func(index int) string {
+	return func(index int) string {
+		if spectral && index < len(o.Bands) {
+			if c, ok := mixBands(o.Bands[index], o.BandColors); ok {
+				return c
+			}
+		}
+		return paint.Paint(data[index])
+	}
+}
+
+// Defs implements the painter.DefsPainter interface and returns the gradients referenced by
+// the boxes
+func (o *BoxPainter) Defs() []string {
+	if o.fills == nil {
+		return nil
+	}
+	return o.fills.Defs(o.totalWidth, o.totalHeight)
+}
+
+// layerColor returns the color of the layer at index, see BoxOptions.LayerColors
+func (o *BoxPainter) layerColor(index int) string {
+	if index < len(o.LayerColors) && o.LayerColors[index] != "" {
+		return o.LayerColors[index]
+	}
+	if index == len(o.Layers)-1 {
+		return o.Color
+	}
+	return DefaultLayerColor
+}
+
+// drawSplit draws data as boxes growing upwards from the canvas's horizontal center
+// axis and lower data as boxes growing downwards from it. Alignment does not apply.
+func (o *BoxPainter) drawSplit(output *strings.Builder, rectTemplate *template.Template, data []float64, lowerData []float64, color func(index int) string) {
+	half := 0.5 * o.BoxHeight
+	minHeight := 0.5 * (o.BoxWidth - o.Gap)
+	for index := range data {
+		upper := data[index] * half
+		if upper < minHeight {
+			upper = minHeight
+		}
+		rectTemplate.Execute(output, o.splitSample(index, half-upper, upper, color(index)))
+
+		var lower float64
+		if index < len(lowerData) {
+			lower = lowerData[index] * half
+		}
+		if lower < minHeight {
+			lower = minHeight
+		}
+		rectTemplate.Execute(output, o.splitSample(index, half, lower, color(index)))
+	}
+}
+
+// drawReflected draws data as boxes growing upwards from the reflection axis and lower data,
+// or data if no lower data is given, as translucent boxes growing downwards from below
+// the axis, scaled by the reflection ratio. Alignment does not apply.
+func (o *BoxPainter) drawReflected(output *strings.Builder, data []float64, lowerData []float64, color func(index int) string) {
+	reflectionTemplate := template.New("reflection")
+	reflectionTemplate.Parse(DefaultReflectionTemplate)
+
+	if lowerData == nil {
+		lowerData = data
+	}
+	var reflectionFill *fill.Fill
+	if o.ReflectionColor != "" {
+		reflectionFill = o.fills.Add(o.ReflectionColor)
+	}
+	// boxes and reflections share the canvas's height without the gap
+	height := math.Max(0, o.BoxHeight-o.ReflectionGap) / (1 + o.Reflection)
+	minHeight := 0.5 * (o.BoxWidth - o.Gap)
+	for index := range data {
+		upper := math.Max(data[index]*height, minHeight)
+		rect := o.splitSample(index, height-upper, upper, color(index))
+		rect.Opacity = 1
+		reflectionTemplate.Execute(output, rect)
+
+		var sample float64
+		if index < len(lowerData) {
+			sample = lowerData[index]
+		}
+		lower := math.Max(sample*height*o.Reflection, o.Reflection*minHeight)
+		reflectionColor := color(index)
+		if reflectionFill != nil {
+			reflectionColor = reflectionFill.Paint(sample)
+		}
+		rect = o.splitSample(index, height+o.ReflectionGap, lower, reflectionColor)
+		rect.Opacity = o.ReflectionOpacity
+		reflectionTemplate.Execute(output, rect)
+	}
+}
+
+// splitSample creates a Rectangle for a box of a given height at vertical position y
+func (o *BoxPainter) splitSample(index int, y float64, height float64, color string) *Rectangle {
+	return &Rectangle{
+		Position: Position{
+			x: float64(index)*o.BoxWidth + (0.5 * o.Gap),
+			y: y,
+		},
+		Dimensions: Dimensions{
+			width:  o.BoxWidth - o.Gap,
+			height: height,
+		},
+		Rounded: o.Rounded,
+		Color:   color,
+	}
 }
 
 // perSample is the handler that creates a Rectangle struct for each sample and
@@ -228,6 +405,24 @@ func (o *BoxPainter) alignBottom(index int, sample float64) *Rectangle {
 	}
 }
 
+// Axis implements the painter.AxisPainter interface. Boxes grow from the reflection axis
+// with reflections, from the center axis for split data, and from their alignment otherwise.
+func (o *BoxPainter) Axis() float64 {
+	if o.Reflection > 0 {
+		return math.Max(0, o.BoxHeight-o.ReflectionGap) / (1 + o.Reflection)
+	}
+	if o.LowerData != nil {
+		return 0.5 * o.BoxHeight
+	}
+	switch o.Alignment {
+	case AlignmentTop:
+		return 0
+	case AlignmentBottom:
+		return o.BoxHeight
+	}
+	return 0.5 * o.BoxHeight
+}
+
 func (o *BoxPainter) Viewbox() string {
 	return fmt.Sprintf("0 0 %f %f", o.totalWidth, o.totalHeight)
 }
@@ -0,0 +1,183 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package box
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/zoomoid/waveman2/pkg/painter"
+	"github.com/zoomoid/waveman2/pkg/transform"
+)
+
+var rectPattern = regexp.MustCompile(`<rect width="([0-9.e+-]+)" height="([0-9.e+-]+)" x="([0-9.e+-]+)" y="([0-9.e+-]+)" rx="[^"]*" ry="[^"]*" fill="([^"]*)"(?: fill-opacity="([0-9.]+)")? />`)
+
+// rects returns the bounds, color, and opacity of all rects in svg
+func rects(t *testing.T, svg string) []Rectangle {
+	var out []Rectangle
+	for _, m := range rectPattern.FindAllStringSubmatch(svg, -1) {
+		var v [4]float64
+		for i := range v {
+			f, err := strconv.ParseFloat(m[i+1], 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v[i] = f
+		}
+		opacity := 1.0
+		if m[6] != "" {
+			o, err := strconv.ParseFloat(m[6], 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			opacity = o
+		}
+		out = append(out, Rectangle{
+			Dimensions: Dimensions{width: v[0], height: v[1]},
+			Position:   Position{x: v[2], y: v[3]},
+			Color:      m[5],
+			Opacity:    opacity,
+		})
+	}
+	return out
+}
+
+// checkBounds reports rects exceeding the painter's viewBox
+func checkBounds(t *testing.T, b *BoxPainter, found []Rectangle) {
+	t.Helper()
+	const epsilon = 1e-9
+	for _, r := range found {
+		if r.X() < -epsilon || r.X()+r.Width() > b.Width()+epsilon || r.Y() < -epsilon || r.Y()+r.Height() > b.Height()+epsilon {
+			t.Errorf("expected rect %+v within the viewBox %s", r, b.Viewbox())
+		}
+	}
+}
+
+func TestDrawSplit(t *testing.T) {
+	b := NewPainter(&painter.PainterOptions{
+		Data:      []float64{1, 0.5, 0},
+		LowerData: []float64{0.25, 1, 0.5},
+	}, &BoxOptions{
+		Gap: DefaultGap,
+	})
+
+	found := rects(t, strings.Join(b.Draw(), ""))
+	if len(found) != 6 {
+		t.Fatalf("expected 6 boxes, found %d", len(found))
+	}
+	checkBounds(t, b, found)
+	half := 0.5 * b.BoxHeight
+	for i := 0; i < len(found); i += 2 {
+		upper, lower := found[i], found[i+1]
+		// upper boxes end at the center axis, and lower boxes start there
+		if math.Abs(upper.Y()+upper.Height()-half) > 1e-9 || lower.Y() != half {
+			t.Errorf("box %d: expected boxes to meet at %g, found %+v and %+v", i/2, half, upper, lower)
+		}
+	}
+	if h := found[3].Height(); h != half {
+		t.Errorf("expected lower box of a full sample to span half the canvas, found %g", h)
+	}
+}
+
+func TestDrawReflected(t *testing.T) {
+	data := []float64{1, 0.5, 0.25}
+	for _, lower := range [][]float64{nil, {0.5, 1, 0}} {
+		b := NewPainter(&painter.PainterOptions{
+			Data:      data,
+			LowerData: lower,
+		}, &BoxOptions{
+			Gap:               DefaultGap,
+			Reflection:        0.5,
+			ReflectionGap:     DefaultReflectionGap,
+			ReflectionOpacity: DefaultReflectionOpacity,
+		})
+
+		found := rects(t, strings.Join(b.Draw(), ""))
+		if len(found) != 2*len(data) {
+			t.Fatalf("expected %d boxes, found %d", 2*len(data), len(found))
+		}
+		checkBounds(t, b, found)
+		axis := b.Axis()
+		reflected := lower
+		if reflected == nil {
+			reflected = data
+		}
+		for i := range data {
+			box, reflection := found[2*i], found[2*i+1]
+			if math.Abs(box.Y()+box.Height()-axis) > 1e-9 {
+				t.Errorf("box %d: expected box to end at the axis %g, found %+v", i, axis, box)
+			}
+			if reflection.Y() != axis+b.ReflectionGap || reflection.Opacity != b.ReflectionOpacity {
+				t.Errorf("box %d: expected translucent reflection below the axis, found %+v", i, reflection)
+			}
+			// full samples reach the top of the canvas, and their reflections its bottom
+			if reflected[i] == 1 && math.Abs(reflection.Y()+reflection.Height()-b.Height()) > 1e-9 {
+				t.Errorf("box %d: expected reflection to end at %g, found %+v", i, b.Height(), reflection)
+			}
+		}
+		if found[0].Y() != 0 {
+			t.Errorf("expected box of a full sample to start at the top, found %+v", found[0])
+		}
+	}
+}
+
+func TestMixBands(t *testing.T) {
+	tests := []struct {
+		bands    transform.Bands
+		expected string
+		ok       bool
+	}{
+		{transform.Bands{Low: 1}, "#ff0000", true},
+		// the dominant band is at full intensity regardless of the total energy
+		{transform.Bands{Low: 0.01, Mid: 0.01}, "#ffff00", true},
+		{transform.Bands{Low: 0.5, Mid: 1, High: 0.25}, "#80ff40", true},
+		{transform.Bands{}, "", false},
+	}
+	for _, test := range tests {
+		mixed, ok := mixBands(test.bands, DefaultBandColors)
+		if mixed != test.expected || ok != test.ok {
+			t.Errorf("%+v: expected %q %t, found %q %t", test.bands, test.expected, test.ok, mixed, ok)
+		}
+	}
+	if _, ok := mixBands(transform.Bands{Low: 1}, []string{"red", "green", "blue"}); ok {
+		t.Error("expected colors other than hex triplets not to be mixed")
+	}
+}
+
+func TestDrawBands(t *testing.T) {
+	data := []float64{1, 0.5, 0.25}
+	b := NewPainter(&painter.PainterOptions{
+		Data:  data,
+		Bands: []transform.Bands{{Low: 1}, {High: 1}, {}},
+	}, &BoxOptions{
+		Color: "#123456",
+	})
+
+	found := rects(t, strings.Join(b.Draw(), ""))
+	if len(found) != len(data) {
+		t.Fatalf("expected %d boxes, found %d", len(data), len(found))
+	}
+	// silent bands fall back to the box color
+	for i, expected := range []string{"#ff0000", "#0000ff", "#123456"} {
+		if found[i].Color != expected {
+			t.Errorf("box %d: expected color %s, found %s", i, expected, found[i].Color)
+		}
+	}
+}
@@ -67,20 +67,32 @@ func (b *BoxPlugin) Flags(flags *pflag.FlagSet) error {
 	if !ok {
 		return errors.New("box data struct is malformed")
 	}
-	flags.StringVar(&data.color, "color", DefaultColor, "Fill color of each box")
+	flags.StringVar(&data.color, "color", DefaultColor, "Fill color of each box, or a gradient or color stops, e.g., 'linear-gradient(to right, #f60, #90f)'")
+	flags.StringSliceVar(&data.layerColors, "layer-colors", nil, "Fill colors of each layer given with --layers, ordered from back to front. Layers without a color use --color for the topmost layer and a translucent black for all others")
+	flags.StringSliceVar(&data.bandColors, "band-colors", DefaultBandColors, "Hex colors of the low, mid, and high frequency band mixed to color each box with --bands")
 	flags.StringVar(&data.alignment, "alignment", string(DefaultAlignment), "Alignment of the shapes, chose one of 'top', 'center', or 'bottom'")
 	flags.Float64Var(&data.rounded, "rounded", DefaultRounded, "Rounding factor of each box. Given in pixels. See SVG <rect> rx/ry attributes for details")
 	flags.Float64Var(&data.gap, "gap", DefaultGap, "Gap is the spacing left between each box. Boxes are centered horizonally, so half of gap is subtracted from the box's width")
+	flags.Float64Var(&data.reflection, "reflection", DefaultReflection, "Height of the reflection below each box relative to the box's height. 0 disables reflections")
+	flags.Float64Var(&data.reflectionGap, "reflection-gap", DefaultReflectionGap, "Vertical spacing between the boxes and their reflections")
+	flags.StringVar(&data.reflectionColor, "reflection-color", "", "Fill color, gradient, or color stops of the reflections. Reflections use the color of their box if empty")
+	flags.Float64Var(&data.reflectionOpacity, "reflection-opacity", DefaultReflectionOpacity, "Fill opacity of the reflections")
 	return nil
 }
 
 func (b *BoxPlugin) Completions(cmd *cobra.Command) {
 	cmd.RegisterFlagCompletionFunc("color", cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc("layer-colors", cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc("band-colors", cobra.NoFileCompletions)
 	cmd.RegisterFlagCompletionFunc("alignment", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return Alignments, cobra.ShellCompDirectiveNoFileComp
 	})
 	cmd.RegisterFlagCompletionFunc("rounded", cobra.NoFileCompletions)
 	cmd.RegisterFlagCompletionFunc("gap", cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc("reflection", cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc("reflection-gap", cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc("reflection-color", cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc("reflection-opacity", cobra.NoFileCompletions)
 }
 
 func (b *BoxPlugin) Draw(options *painter.PainterOptions) []string {
@@ -94,12 +106,19 @@ func (b *BoxPlugin) Painter() painter.Painter {
 }
 
 type boxData struct {
-	color     string
-	alignment string
-	height    float64
-	width     float64
-	rounded   float64
-	gap       float64
+	color       string
+	layerColors []string
+	bandColors  []string
+	alignment   string
+	height      float64
+	width       float64
+	rounded     float64
+	gap         float64
+
+	reflection        float64
+	reflectionGap     float64
+	reflectionColor   string
+	reflectionOpacity float64
 }
 
 func (b *boxData) validateBoxOptions() (errList []error) {
@@ -109,6 +128,29 @@ func (b *boxData) validateBoxOptions() (errList []error) {
 	if err := validateGap(b.gap, b.width); err != nil {
 		errList = append(errList, err)
 	}
+	if err := validateBandColors(b.bandColors); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validateReflection(b.reflection); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validateReflectionGap(b.reflectionGap); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validateReflectionOpacity(b.reflectionOpacity); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validateFill("color", b.color); err != nil {
+		errList = append(errList, err)
+	}
+	for _, color := range b.layerColors {
+		if err := validateFill("layer-colors", color); err != nil {
+			errList = append(errList, err)
+		}
+	}
+	if err := validateFill("reflection-color", b.reflectionColor); err != nil {
+		errList = append(errList, err)
+	}
 	if len(errList) == 0 {
 		return nil
 	}
@@ -117,23 +159,35 @@ func (b *boxData) validateBoxOptions() (errList []error) {
 
 func (b *boxData) toOptions(width float64, height float64) *BoxOptions {
 	p := &BoxOptions{
-		Alignment: Alignment(b.alignment),
-		Color:     b.color,
-		BoxHeight: height,
-		BoxWidth:  width,
-		Rounded:   b.rounded,
-		Gap:       b.gap,
+		Alignment:   Alignment(b.alignment),
+		Color:       b.color,
+		LayerColors: b.layerColors,
+		BandColors:  b.bandColors,
+		BoxHeight:   height,
+		BoxWidth:    width,
+		Rounded:     b.rounded,
+		Gap:         b.gap,
+
+		Reflection:        b.reflection,
+		ReflectionGap:     b.reflectionGap,
+		ReflectionColor:   b.reflectionColor,
+		ReflectionOpacity: b.reflectionOpacity,
 	}
 	return p
 }
 
 func newBoxData() *boxData {
 	return &boxData{
-		color:     DefaultColor,
-		alignment: string(DefaultAlignment),
-		height:    DefaultHeight,
-		width:     DefaultWidth,
-		rounded:   DefaultRounded,
-		gap:       DefaultRounded,
+		color:      DefaultColor,
+		bandColors: DefaultBandColors,
+		alignment:  string(DefaultAlignment),
+		height:     DefaultHeight,
+		width:      DefaultWidth,
+		rounded:    DefaultRounded,
+		gap:        DefaultRounded,
+
+		reflection:        DefaultReflection,
+		reflectionGap:     DefaultReflectionGap,
+		reflectionOpacity: DefaultReflectionOpacity,
 	}
 }
@@ -59,4 +59,6 @@ type Rectangle struct {
 	Rounded float64
 	// Color is the fill color of the rectangle
 	Color string
+	// Opacity is the fill opacity of the rectangle, used by reflections only
+	Opacity float64
 }
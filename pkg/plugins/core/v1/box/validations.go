@@ -19,6 +19,8 @@ package box
 import (
 	"errors"
 	"fmt"
+
+	"github.com/zoomoid/waveman2/pkg/painter/fill"
 )
 
 func validateAlignment(alignment string) error {
@@ -39,3 +41,43 @@ func validateGap(gap float64, width float64) error {
 	}
 	return nil
 }
+
+func validateBandColors(colors []string) error {
+	if len(colors) != 3 {
+		return errors.New("--band-colors requires exactly three colors for the low, mid, and high band")
+	}
+	for _, color := range colors {
+		if _, err := fill.ParseHexColor(color); err != nil {
+			return fmt.Errorf("--band-colors: %w", err)
+		}
+	}
+	return nil
+}
+
+func validateReflection(reflection float64) error {
+	if reflection < 0 || reflection > 1 {
+		return errors.New("--reflection must be in [0,1]")
+	}
+	return nil
+}
+
+func validateReflectionGap(gap float64) error {
+	if gap < 0 {
+		return errors.New("--reflection-gap must be non-negative")
+	}
+	return nil
+}
+
+func validateReflectionOpacity(opacity float64) error {
+	if opacity < 0 || opacity > 1 {
+		return errors.New("--reflection-opacity must be in [0,1]")
+	}
+	return nil
+}
+
+func validateFill(flag string, spec string) error {
+	if err := fill.Validate(spec); err != nil {
+		return fmt.Errorf("--%s: %w", flag, err)
+	}
+	return nil
+}
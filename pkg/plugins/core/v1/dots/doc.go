@@ -0,0 +1,50 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dots
+
+import "github.com/lithammer/dedent"
+
+var (
+	group string = "core/v1"
+
+	description string = dedent.Dedent(`
+		The dots painter draws a column of circles for each data point.
+
+		--mapping determines how a data point is mapped onto its dots: "radius" scales 
+		each dot's radius with the data point, "area" scales each dot's area, and 
+		"opacity" draws dots at full size and scales their opacity instead.
+
+		--stack sets the number of dots per column. By default, each column consists of
+		a single dot. With more than one dot, dots light up one after another from the 
+		alignment axis outwards as the data point grows, like the segments of an LED meter,
+		and only the outermost lit dot is partially scaled by --mapping.
+
+		The alignment axis can be either "top", "center", or "bottom", and set with 
+		--alignment. With center alignment, stacked dots light up symmetrically in 
+		both directions.
+
+		The dots' color can be set with --color, which also takes gradients, or color
+		stops mapping each column's data point to the color of its dots.
+
+		--height (or -h) sets the height of the entire canvas, and --width (or -w) sets 
+		the width of each column.
+
+		--gap sets the space left between adjacent dots. A dot's diameter at full size is
+		the smaller one of the column's width and the height of a dot's share of the
+		column, reduced by the gap.
+	`)
+)
@@ -0,0 +1,279 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dots
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"text/template"
+
+	"github.com/zoomoid/waveman2/pkg/painter"
+	"github.com/zoomoid/waveman2/pkg/painter/fill"
+)
+
+// Alignment is the categorical type for determining the dots' alignment axis
+type Alignment string
+
+const (
+	// AlignmentTop pulls all dots to the canvas's upper boundary
+	AlignmentTop Alignment = "top"
+	// AlignmentCenter centers all dots in the canvas's horizontal center axis
+	AlignmentCenter Alignment = "center"
+	// AlignmentBottom pulls all dots to the canvas's lower boundary
+	AlignmentBottom Alignment = "bottom"
+	// AlignmentEmpty is used for catching unitialized alignment
+	AlignmentEmpty Alignment = ""
+)
+
+var Alignments = []string{"center", "top", "bottom"}
+
+// Mapping determines how a dot's level, i.e., the share of its slot covered by the sample,
+// is mapped onto its appearance
+type Mapping string
+
+const (
+	// MappingRadius scales a dot's radius linearly with its level
+	MappingRadius Mapping = "radius"
+	// MappingArea scales a dot's area linearly with its level, i.e., its radius with the
+	// square root of its level
+	MappingArea Mapping = "area"
+	// MappingOpacity draws all dots at full size and scales their opacity with their level
+	MappingOpacity Mapping = "opacity"
+	// MappingEmpty is used for catching unitialized mappings
+	MappingEmpty Mapping = ""
+)
+
+var Mappings = []string{"radius", "area", "opacity"}
+
+const (
+	DefaultCircleTemplate = `<circle cx="{{.X}}" cy="{{.Y}}" r="{{.Radius}}" fill="{{.Color}}" fill-opacity="{{.Opacity}}" />`
+)
+
+const (
+	// DefaultColor for dots is black
+	DefaultColor = "black"
+	// DefaultAlignment for dots is center
+	DefaultAlignment = AlignmentCenter
+	// DefaultMapping scales the dots' radius
+	DefaultMapping = MappingRadius
+	// DefaultHeight of a canvas is 200px
+	DefaultHeight = float64(200)
+	// DefaultWidth of each column of dots is 20px
+	DefaultWidth = float64(20)
+	// DefaultGap between adjacent dots is 5px
+	DefaultGap = float64(5)
+	// DefaultStack draws a single dot per column
+	DefaultStack = 1
+)
+
+// Compile-time type checking for DotsPainter to implement all functions required
+// by the Painter interface
+var _ painter.Painter = &DotsPainter{}
+
+type DotsOptions struct {
+	// Color for each dot, in a CSS-compliant format or as a fill specification, see package
+	// fill. Color stops map each column's sample to its color.
+	Color string
+	// Alignment of the dots, either top, center, or bottom
+	Alignment Alignment
+	// Mapping determines whether a dot's radius, area, or opacity scales with its level
+	Mapping Mapping
+	// Stack is the number of dots per column. Stacked dots light up one after another from
+	// the alignment axis outwards as the sample grows, like the segments of an LED meter.
+	Stack int
+	// DotHeight is the factor by which each sample value gets scaled upwards, and thus the
+	// total height of the graphic
+	DotHeight float64
+	// DotWidth is the absolute width of each column of dots, including the gap
+	DotWidth float64
+	// Gap is the spacing between adjacent dots, both horizontally and vertically
+	Gap float64
+	// totalWidth is the canvas's width that results from adding up each column's width
+	totalWidth float64
+	// totalHeight is the canvas's total height
+	totalHeight float64
+}
+
+// DotsPainter is the struct containing context for drawing a waveform as SVG circles
+type DotsPainter struct {
+	// Embed all painter options, i.e., data points
+	*painter.PainterOptions
+	// Embed all options for the dots painter
+	*DotsOptions
+	// fills collects the fills of the last call to Draw
+	fills *fill.Set
+}
+
+// Circle is a single dot with its center, radius, color, and opacity
+type Circle struct {
+	X       float64
+	Y       float64
+	Radius  float64
+	Color   string
+	Opacity float64
+}
+
+// Height returns the canvas's total height
+func (o *DotsPainter) Height() float64 {
+	return o.totalHeight
+}
+
+// Width returns the canvas's total width. This is equal to the number of
+// samples times the width of each column.
+func (o *DotsPainter) Width() float64 {
+	return o.totalWidth
+}
+
+// NewPainter constructs a new Dots painter with the passed options and fills in
+// defaults for missing fields
+func NewPainter(painter *painter.PainterOptions, options *DotsOptions) *DotsPainter {
+	if options.Color == "" {
+		options.Color = DefaultColor
+	}
+	if options.Alignment == AlignmentEmpty {
+		options.Alignment = DefaultAlignment
+	}
+	if options.Mapping == MappingEmpty {
+		options.Mapping = DefaultMapping
+	}
+	if options.Stack == 0 {
+		options.Stack = DefaultStack
+	}
+	if options.DotHeight == 0 {
+		options.DotHeight = DefaultHeight
+	}
+	if options.DotWidth == 0 {
+		options.DotWidth = DefaultWidth
+	}
+
+	options.totalHeight = options.DotHeight
+	options.totalWidth = options.DotWidth * float64(len(painter.Data))
+	return &DotsPainter{
+		PainterOptions: painter,
+		DotsOptions:    options,
+	}
+}
+
+// Draw implements the Painter interface's required Draw() function. For each sample, a
+// column of SVG circles is created, and all of them are wrapped inside an SVG group element.
+func (o *DotsPainter) Draw() []string {
+	output := &strings.Builder{}
+
+	circleTemplate := template.New("circle")
+	circleTemplate.Parse(DefaultCircleTemplate)
+
+	o.fills = fill.NewSet(o.IDPrefix("dots"))
+	paint := o.fills.Add(o.Color)
+	output.WriteString("<g>")
+	for index, sample := range o.Data {
+		for _, circle := range o.perSample(index, sample, paint.Paint(sample)) {
+			circleTemplate.Execute(output, circle)
+		}
+	}
+	output.WriteString("</g>")
+	return []string{output.String()}
+}
+
+// slot returns the vertical center of the dot's slot at index k as well as the distances of
+// the slot's near and far edge from the alignment axis. Slots evenly divide the canvas's
+// height. For center alignment, the middle slot of an odd number of slots straddles the axis.
+func (o *DotsPainter) slot(k int) (y float64, near float64, far float64) {
+	pitch := o.DotHeight / float64(o.Stack)
+	switch o.Alignment {
+	case AlignmentTop:
+		return (float64(k) + 0.5) * pitch, float64(k) * pitch, float64(k+1) * pitch
+	case AlignmentBottom:
+		return o.DotHeight - (float64(k)+0.5)*pitch, float64(k) * pitch, float64(k+1) * pitch
+	}
+	y = (float64(k) + 0.5) * pitch
+	distance := math.Abs(y - 0.5*o.DotHeight)
+	return y, math.Max(0, distance-0.5*pitch), distance + 0.5*pitch
+}
+
+// radius returns the radius of a dot at full level, which is bounded by both the column's
+// width and the slot's height, each reduced by the gap
+func (o *DotsPainter) radius() float64 {
+	pitch := o.DotHeight / float64(o.Stack)
+	return 0.5 * math.Max(0, math.Min(o.DotWidth, pitch)-o.Gap)
+}
+
+// perSample creates the circles of the column of a sample and its index in the given color.
+// Each slot's level is the share of the slot covered by the sample, measured from the
+// alignment axis outwards, and slots the sample does not reach are omitted.
+func (o *DotsPainter) perSample(index int, sample float64, color string) []*Circle {
+	extent := sample * o.DotHeight
+	if o.Alignment == AlignmentCenter {
+		extent *= 0.5
+	}
+	x := (float64(index) + 0.5) * o.DotWidth
+	full := o.radius()
+
+	circles := []*Circle{}
+	for k := 0; k < o.Stack; k++ {
+		y, near, far := o.slot(k)
+		level := math.Min(1, (extent-near)/(far-near))
+		if level <= 0 {
+			continue
+		}
+		circle := &Circle{
+			X:       x,
+			Y:       o.align(y, full),
+			Radius:  full,
+			Color:   color,
+			Opacity: 1,
+		}
+		switch o.Mapping {
+		case MappingRadius:
+			circle.Radius = level * full
+		case MappingArea:
+			circle.Radius = math.Sqrt(level) * full
+		case MappingOpacity:
+			circle.Opacity = level
+		}
+		circles = append(circles, circle)
+	}
+	return circles
+}
+
+// align moves a dot's center within a single slot spanning the entire height towards the
+// alignment axis, such that a single dot per column follows the alignment as well
+func (o *DotsPainter) align(y float64, radius float64) float64 {
+	if o.Stack > 1 {
+		return y
+	}
+	switch o.Alignment {
+	case AlignmentTop:
+		return radius + 0.5*o.Gap
+	case AlignmentBottom:
+		return o.DotHeight - radius - 0.5*o.Gap
+	}
+	return y
+}
+
+// Defs implements the painter.DefsPainter interface and returns the gradients referenced by
+// the dots
+func (o *DotsPainter) Defs() []string {
+	if o.fills == nil {
+		return nil
+	}
+	return o.fills.Defs(o.totalWidth, o.totalHeight)
+}
+
+func (o *DotsPainter) Viewbox() string {
+	return fmt.Sprintf("0 0 %f %f", o.totalWidth, o.totalHeight)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dots
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/zoomoid/waveman2/pkg/painter"
+)
+
+var circlePattern = regexp.MustCompile(`<circle cx="([0-9.]+)" cy="([0-9.]+)" r="([0-9.]+)" fill="[^"]*" fill-opacity="([0-9.]+)" />`)
+
+// circles returns the center, radius, and opacity of all circles in svg
+func circles(t *testing.T, svg string) []Circle {
+	var out []Circle
+	for _, m := range circlePattern.FindAllStringSubmatch(svg, -1) {
+		var v [4]float64
+		for i := range v {
+			f, err := strconv.ParseFloat(m[i+1], 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v[i] = f
+		}
+		out = append(out, Circle{X: v[0], Y: v[1], Radius: v[2], Opacity: v[3]})
+	}
+	return out
+}
+
+func TestDrawRadius(t *testing.T) {
+	data := []float64{0.25, 1, 0, 0.5}
+	d := NewPainter(&painter.PainterOptions{
+		Data: data,
+	}, &DotsOptions{
+		Gap: DefaultGap,
+	})
+
+	// silent samples have no dot
+	found := circles(t, strings.Join(d.Draw(), ""))
+	if len(found) != 3 {
+		t.Fatalf("expected 3 dots, found %d", len(found))
+	}
+	full := found[1].Radius
+	for i, c := range []int{0, 1, 3} {
+		if expected := data[c] * full; math.Abs(found[i].Radius-expected) > 1e-9 {
+			t.Errorf("dot %d: expected radius %g, found %g", c, expected, found[i].Radius)
+		}
+	}
+}
+
+func TestDrawStack(t *testing.T) {
+	data := []float64{1, 0.5, 0.125}
+	for _, alignment := range []Alignment{AlignmentTop, AlignmentCenter, AlignmentBottom} {
+		d := NewPainter(&painter.PainterOptions{
+			Data: data,
+		}, &DotsOptions{
+			Alignment: alignment,
+			Mapping:   MappingOpacity,
+			Stack:     4,
+			Gap:       DefaultGap,
+		})
+
+		found := circles(t, strings.Join(d.Draw(), ""))
+		columns := map[float64]int{}
+		for _, c := range found {
+			columns[c.X]++
+			if c.X-c.Radius < 0 || c.X+c.Radius > d.Width() || c.Y-c.Radius < 0 || c.Y+c.Radius > d.Height() {
+				t.Errorf("%s: expected dot %+v within the viewBox %s", alignment, c, d.Viewbox())
+			}
+		}
+		// the number of lit dots grows with the sample, on both sides of the center axis
+		for i, sample := range data {
+			x := (float64(i) + 0.5) * d.DotWidth
+			expected := int(math.Ceil(sample * 4))
+			if alignment == AlignmentCenter {
+				expected = 2 * int(math.Ceil(sample*2))
+			}
+			if columns[x] != expected {
+				t.Errorf("%s: column %d: expected %d dots, found %d", alignment, i, expected, columns[x])
+			}
+		}
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dots
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/zoomoid/waveman2/pkg/painter"
+	"github.com/zoomoid/waveman2/pkg/plugin"
+	"github.com/zoomoid/waveman2/pkg/utils"
+)
+
+var _ plugin.Plugin = &DotsPlugin{}
+
+var Plugin plugin.Plugin = &DotsPlugin{
+	data: newDotsData(),
+}
+
+type DotsPlugin struct {
+	data    *dotsData
+	painter *DotsPainter
+}
+
+func (d *DotsPlugin) Group() string {
+	return group
+}
+
+func (d *DotsPlugin) Name() string {
+	return "dots"
+}
+
+func (d *DotsPlugin) Description() string {
+	return description
+}
+
+func (d *DotsPlugin) Data() interface{} {
+	return d.data
+}
+
+func (d *DotsPlugin) Validate() error {
+	errs := d.data.validateDotsOptions()
+	errlist := utils.NewErrorList(errs)
+	if errlist == nil {
+		return nil
+	}
+	return errors.New(errlist.Error())
+}
+
+func (d *DotsPlugin) Flags(flags *pflag.FlagSet) error {
+	data, ok := d.Data().(*dotsData)
+	if !ok {
+		return errors.New("dots data struct is malformed")
+	}
+	flags.StringVar(&data.color, "color", DefaultColor, "Fill color, gradient, or color stops of each dot")
+	flags.StringVar(&data.alignment, "alignment", string(DefaultAlignment), "Alignment of the dots, chose one of 'top', 'center', or 'bottom'")
+	flags.StringVar(&data.mapping, "mapping", string(DefaultMapping), "Property of each dot scaled with the data point, chose one of 'radius', 'area', or 'opacity'")
+	flags.IntVar(&data.stack, "stack", DefaultStack, "Number of dots per column, lit one after another like the segments of an LED meter")
+	flags.Float64Var(&data.gap, "gap", DefaultGap, "Gap is the spacing left between adjacent dots, both horizontally and vertically")
+	return nil
+}
+
+func (d *DotsPlugin) Completions(cmd *cobra.Command) {
+	cmd.RegisterFlagCompletionFunc("color", cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc("alignment", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return Alignments, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.RegisterFlagCompletionFunc("mapping", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return Mappings, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.RegisterFlagCompletionFunc("stack", cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc("gap", cobra.NoFileCompletions)
+}
+
+func (d *DotsPlugin) Draw(options *painter.PainterOptions) []string {
+	painter := NewPainter(options, d.data.toOptions(options.Width, options.Height))
+	d.painter = painter
+	return painter.Draw()
+}
+
+func (d *DotsPlugin) Painter() painter.Painter {
+	return d.painter
+}
+
+type dotsData struct {
+	color     string
+	alignment string
+	mapping   string
+	stack     int
+	height    float64
+	width     float64
+	gap       float64
+}
+
+func (d *dotsData) validateDotsOptions() (errList []error) {
+	if err := validateAlignment(d.alignment); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validateMapping(d.mapping); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validateStack(d.stack); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validateGap(d.gap, d.width); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validateFill("color", d.color); err != nil {
+		errList = append(errList, err)
+	}
+	if len(errList) == 0 {
+		return nil
+	}
+	return errList
+}
+
+func (d *dotsData) toOptions(width float64, height float64) *DotsOptions {
+	return &DotsOptions{
+		Color:     d.color,
+		Alignment: Alignment(d.alignment),
+		Mapping:   Mapping(d.mapping),
+		Stack:     d.stack,
+		DotHeight: height,
+		DotWidth:  width,
+		Gap:       d.gap,
+	}
+}
+
+func newDotsData() *dotsData {
+	return &dotsData{
+		color:     DefaultColor,
+		alignment: string(DefaultAlignment),
+		mapping:   string(DefaultMapping),
+		stack:     DefaultStack,
+		height:    DefaultHeight,
+		width:     DefaultWidth,
+		gap:       DefaultGap,
+	}
+}
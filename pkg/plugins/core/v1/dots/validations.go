@@ -0,0 +1,69 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dots
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zoomoid/waveman2/pkg/painter/fill"
+)
+
+func validateAlignment(alignment string) error {
+	a := Alignment(alignment)
+	switch a {
+	case AlignmentBottom,
+		AlignmentCenter,
+		AlignmentTop,
+		AlignmentEmpty:
+		return nil
+	}
+	return fmt.Errorf("--alignment %s is not supported", alignment)
+}
+
+func validateMapping(mapping string) error {
+	m := Mapping(mapping)
+	switch m {
+	case MappingRadius,
+		MappingArea,
+		MappingOpacity,
+		MappingEmpty:
+		return nil
+	}
+	return fmt.Errorf("--mapping %s is not supported", mapping)
+}
+
+func validateStack(stack int) error {
+	if stack < 1 {
+		return errors.New("--stack must be at least 1")
+	}
+	return nil
+}
+
+func validateGap(gap float64, width float64) error {
+	if gap >= width {
+		return errors.New("--gap must not be greater or equal to --width")
+	}
+	return nil
+}
+
+func validateFill(flag string, spec string) error {
+	if err := fill.Validate(spec); err != nil {
+		return fmt.Errorf("--%s: %w", flag, err)
+	}
+	return nil
+}
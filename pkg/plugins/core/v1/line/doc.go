@@ -32,7 +32,8 @@ var (
 		
 		The color of the line is set with --stroke-color, and the width of the line 
 		with --stroke-width. All those require SVG/CSS-compliant values for the
-		attributes.
+		attributes. --stroke-color also takes gradients, e.g., 
+		"linear-gradient(to right, #f60, #90f)".
 
 		The shape can be horizontally mirrored by setting --inverted (-i).
 
@@ -22,6 +22,7 @@ import (
 	"text/template"
 
 	"github.com/zoomoid/waveman2/pkg/painter"
+	"github.com/zoomoid/waveman2/pkg/painter/fill"
 	"github.com/zoomoid/waveman2/pkg/utils/interpolation"
 )
 
@@ -49,11 +50,12 @@ const (
 var Interpolations = []string{"fritsch-carlson", "none", "steffen", "akima"}
 
 const (
-	DefaultPathTemplate string = `<path d="{{.Path}}" stroke="{{.Stroke.Color}}" stroke-width="{{.Stroke.Width}}" />`
+	DefaultPathTemplate string = `<path d="{{.Path}}"{{if .Fill}} fill="{{.Fill}}"{{end}} stroke="{{.Stroke.Color}}" stroke-width="{{.Stroke.Width}}" />`
 )
 
 type Stroke struct {
-	// Color is the CSS-compliant stroke color used for the path
+	// Color is the CSS-compliant stroke color used for the path, or a fill specification,
+	// see package fill
 	Color string
 	// Width is the stroke width used for the path
 	Width float64
@@ -62,7 +64,8 @@ type Stroke struct {
 type LineOptions struct {
 	// Interpolation choses the point interpolation mode
 	Interpolation Interpolation
-	// Fill is the CSS-compliant color value for the fill
+	// Fill is the CSS-compliant color value for the fill, or a fill specification, see
+	// package fill. Without a fill, paths are filled black as by the SVG default.
 	Fill string
 	// Stroke is a struct defining properties of the stroke, namely color and
 	// width
@@ -86,8 +89,6 @@ const (
 	DefaultStrokeWidth float64 = 0
 	// Default stroke color
 	DefaultStrokeColor string = "none"
-	// Default fill color
-	DefaultFillColor string = "rgba(0 0 0 / 0.5)"
 	// Default horizontal spread of data points
 	DefaultSpread = float64(10)
 	// DefaultHeight of a canvas is 200px
@@ -110,6 +111,8 @@ type LinePainter struct {
 	*painter.PainterOptions
 	// Embed all options for the line painter
 	*LineOptions
+	// fills collects the fills of the last call to Draw
+	fills *fill.Set
 }
 
 // NewPainter constructs a new Line painter with the passed options and fills in defaults
@@ -118,9 +121,6 @@ func NewPainter(painter *painter.PainterOptions, options *LineOptions) *LinePain
 	if options.Interpolation == InterpolationEmpty {
 		options.Interpolation = DefaultInterpolation
 	}
-	if options.Fill == "" {
-		options.Fill = DefaultFillColor
-	}
 	if options.Stroke == nil {
 		options.Stroke = &Stroke{
 			Color: DefaultStrokeColor,
@@ -174,26 +174,73 @@ func (l *LinePainter) Draw() []string {
 	pathTemplate := template.New("path")
 	pathTemplate.Parse(DefaultPathTemplate)
 
-	var direction float64 = -1
-	var offset float64 = l.Amplitude
-	if l.Inverted {
-		direction = 1
-		offset = 0
+	var paths []string
+	if l.LowerEnvelope != nil {
+		// signed envelopes are drawn around the canvas's horizontal center axis, upwards
+		// for positive values, or downwards when inverted
+		var direction float64 = -1
+		if l.Inverted {
+			direction = 1
+		}
+		paths = append(paths,
+			l.path(l.Data, direction, 0.5*l.Amplitude, 0.5*l.Amplitude),
+			l.path(l.LowerEnvelope, direction, 0.5*l.Amplitude, 0.5*l.Amplitude),
+		)
+	} else if l.LowerData != nil {
+		// split the canvas at its horizontal center axis, drawing Data upwards and LowerData
+		// downwards, or vice versa when inverted
+		upper, lower := l.Data, l.LowerData
+		if l.Inverted {
+			upper, lower = lower, upper
+		}
+		paths = append(paths,
+			l.path(upper, -1, 0.5*l.Amplitude, 0.5*l.Amplitude),
+			l.path(lower, 1, 0.5*l.Amplitude, 0.5*l.Amplitude),
+		)
+	} else if l.Inverted {
+		paths = append(paths, l.path(l.Data, 1, 0, l.Amplitude))
+	} else {
+		paths = append(paths, l.path(l.Data, -1, l.Amplitude, l.Amplitude))
 	}
 
-	// make a slice of pairs that have the spread x values and their y values paired
+	l.fills = fill.NewSet(l.IDPrefix("line"))
+	peak := fill.Max(l.Data, l.LowerData)
+	var area string
+	if l.Fill != "" {
+		area = l.fills.Add(l.Fill).Paint(peak)
+	}
+	stroke := &Stroke{
+		Color: l.fills.Add(l.Stroke.Color).Paint(peak),
+		Width: l.Stroke.Width,
+	}
 
-	var samples [][2]float64
+	output.WriteString(`<g style="transform-origin: center center;">`)
+	for _, line := range paths {
+		bindings := templateBindings{
+			Fill:   area,
+			Path:   line,
+			Stroke: stroke,
+		}
+		pathTemplate.Execute(output, bindings)
+	}
+	output.WriteString(`</g>`)
 
-	samples = make([][2]float64, 0, len(l.Data)+2)
+	return []string{output.String()}
+}
+
+// path creates the interpolated path of a series of samples, which starts and ends at the
+// vertical offset, and whose points are scaled by amplitude in the given direction
+func (l *LinePainter) path(data []float64, direction float64, offset float64, amplitude float64) string {
+	// make a slice of pairs that have the spread x values and their y values paired
+	samples := make([][2]float64, 0, len(data)+2)
 
 	// start point
 	samples = append(samples, [2]float64{0, offset})
-	for i, sample := range l.Data {
+	for i, sample := range data {
 		// offset samples in X direction by one unit of spread to account for start points
 		samples = append(samples, [2]float64{
 			float64(i+1) * l.Spread,
-			direction*l.Amplitude*sample + offset,
+			direction*amplitude*sample + offset,
 		})
 	}
 	// end point
@@ -214,18 +261,28 @@ func (l *LinePainter) Draw() []string {
 	if l.Closed {
 		line += " Z\n"
 	}
+	return line
+}
 
-	bindings := templateBindings{
-		Fill:   l.Fill,
-		Path:   line,
-		Stroke: l.Stroke,
+// Defs implements the painter.DefsPainter interface and returns the gradients referenced by
+// the line's fill and stroke
+func (l *LinePainter) Defs() []string {
+	if l.fills == nil {
+		return nil
 	}
+	return l.fills.Defs(l.Width(), l.Height())
+}
 
-	output.WriteString(`<g style="transform-origin: center center;">`)
-	pathTemplate.Execute(output, bindings)
-	output.WriteString(`</g>`)
-
-	return []string{output.String()}
+// Axis implements the painter.AxisPainter interface. Lines start and end at the axis, which
+// is the canvas's center for envelopes and split data, and its top or bottom otherwise.
+func (l *LinePainter) Axis() float64 {
+	if l.LowerEnvelope != nil || l.LowerData != nil {
+		return 0.5 * l.Amplitude
+	}
+	if l.Inverted {
+		return 0
+	}
+	return l.Amplitude
 }
 
 func (l *LinePainter) Viewbox() string {
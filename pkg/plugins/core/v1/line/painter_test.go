@@ -0,0 +1,85 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package line
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zoomoid/waveman2/pkg/painter"
+)
+
+func TestDrawGradientFill(t *testing.T) {
+	l := NewPainter(&painter.PainterOptions{
+		Data: []float64{0.2, 0.8, 0.5, 0.3},
+	}, &LineOptions{
+		Closed: true,
+		Fill:   "linear-gradient(to right, #f60, #90f)",
+	})
+
+	path := strings.Join(l.Draw(), "")
+	if !strings.Contains(path, `fill="url(#line-fill-0)"`) {
+		t.Errorf("expected path to reference the fill gradient, found %s", path)
+	}
+
+	defs := strings.Join(l.Defs(), "")
+	if !strings.Contains(defs, `<linearGradient id="line-fill-0"`) {
+		t.Errorf("expected a linear gradient definition for the fill, found %s", defs)
+	}
+}
+
+func TestDrawSolidFill(t *testing.T) {
+	// without a configured fill, the path keeps the SVG default fill
+	l := NewPainter(&painter.PainterOptions{
+		Data: []float64{0.2, 0.8, 0.5, 0.3},
+	}, &LineOptions{})
+
+	path := strings.Join(l.Draw(), "")
+	if strings.Contains(path, "fill=") {
+		t.Errorf("expected path without fill attribute, found %s", path)
+	}
+
+	l = NewPainter(&painter.PainterOptions{
+		Data: []float64{0.2, 0.8, 0.5, 0.3},
+	}, &LineOptions{Fill: "rgba(0 0 0 / 0.5)"})
+
+	path = strings.Join(l.Draw(), "")
+	if !strings.Contains(path, `fill="rgba(0 0 0 / 0.5)"`) {
+		t.Errorf("expected path to be filled with the configured color, found %s", path)
+	}
+	if len(l.Defs()) != 0 {
+		t.Errorf("expected no definitions for solid colors, found %v", l.Defs())
+	}
+}
+
+func TestDrawID(t *testing.T) {
+	// SVGs inlined into the same page need distinct gradient IDs
+	l := NewPainter(&painter.PainterOptions{
+		Data: []float64{0.2, 0.8, 0.5, 0.3},
+		ID:   "track-1",
+	}, &LineOptions{
+		Fill: "linear-gradient(to right, #f60, #90f)",
+	})
+
+	path := strings.Join(l.Draw(), "")
+	if !strings.Contains(path, `fill="url(#track-1-line-fill-0)"`) {
+		t.Errorf("expected path to reference the prefixed gradient, found %s", path)
+	}
+	if defs := strings.Join(l.Defs(), ""); !strings.Contains(defs, `id="track-1-line-fill-0"`) {
+		t.Errorf("expected prefixed gradient definition, found %s", defs)
+	}
+}
@@ -68,8 +68,8 @@ func (l *LinePlugin) Flags(flags *pflag.FlagSet) error {
 		return errors.New("line data struct is malformed")
 	}
 	flags.StringVar(&data.interpolation, "interpolation", string(DefaultInterpolation), "Interpolation mechanism to be used for smoothing the curve [none,fritsch-carlson,steffen]")
-	flags.StringVar(&data.fill, "fill-color", DefaultFillColor, "Color for the area enclosed by the line")
-	flags.StringVar(&data.strokeColor, "stroke-color", DefaultStrokeColor, "Color of the line's stroke")
+	flags.StringVar(&data.fill, "fill-color", "", "Color or gradient for the area enclosed by the line, which is filled black if empty")
+	flags.StringVar(&data.strokeColor, "stroke-color", DefaultStrokeColor, "Color or gradient of the line's stroke")
 	flags.Float64Var(&data.strokeWidth, "stroke-width", DefaultStrokeWidth, "Width of the line's stroke")
 	flags.BoolVarP(&data.closed, "closed", "c", false, "Whether the SVG path should be closed or left open")
 	flags.BoolVarP(&data.inverted, "inverted", "i", false, "Whether the shape should be inverted horizontally, i.e., switch the vertical alignment from top to bottom")
@@ -116,6 +116,12 @@ func (l *lineData) validateLineOptions() (errList []error) {
 	if err := validateInterpolation(l.interpolation); err != nil {
 		errList = append(errList, err)
 	}
+	if err := validateFill("fill-color", l.fill); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validateFill("stroke-color", l.strokeColor); err != nil {
+		errList = append(errList, err)
+	}
 	if len(errList) == 0 {
 		return nil
 	}
@@ -137,7 +143,6 @@ type lineData struct {
 func newLineData() *lineData {
 	return &lineData{
 		interpolation: string(DefaultInterpolation),
-		fill:          DefaultFillColor,
 		strokeColor:   DefaultStrokeColor,
 		strokeWidth:   DefaultStrokeWidth,
 		spread:        DefaultSpread,
@@ -0,0 +1,52 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package radial
+
+import "github.com/lithammer/dedent"
+
+var (
+	group string = "core/v1"
+
+	description string = dedent.Dedent(`
+		The radial painter wraps the data points around a circle. Each data point is drawn
+		at an angle proportional to its index, and grows outwards from an inner circle
+		proportionally to its value.
+
+		--style selects the shape of the data points: "bars" draws a wedge-shaped bar for
+		each data point, "line" connects all data points with a single line.
+
+		--inner-radius sets the radius of the inner circle, and the --height (or -h) flag
+		controls the length of a bar for a data point of 1. The canvas is a square with a 
+		side length of twice the sum of the two.
+
+		--start-angle sets the angle of the first data point in degrees, measured clockwise
+		from the top of the circle. --sweep-angle sets the angle covered by all data points,
+		which defaults to the full circle. When the full circle is covered, the line style
+		connects the last data point to the first one.
+
+		For the bars style, --gap controls the share of each bar's angular span that is left 
+		empty between adjacent bars, and --color sets the bars' color. Color stops, e.g.,
+		"stops(#00f, #f0f)", color each bar by its data point, and radial gradients
+		spread out from the circle's center.
+
+		For the line style, --color and --stroke-width control the line's stroke, and
+		--fill-color the area enclosed by the line. Like the line and sweep painters, 
+		the line is smoothed by interpolation, using the Fritsch-Carlson scheme by default.
+		Setting "--interpolation steffen" or "--interpolation akima" selects the other 
+		schemes, and "--interpolation none" disables interpolation entirely.
+	`)
+)
@@ -0,0 +1,324 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package radial
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"text/template"
+
+	"github.com/zoomoid/waveman2/pkg/painter"
+	"github.com/zoomoid/waveman2/pkg/painter/fill"
+	"github.com/zoomoid/waveman2/pkg/utils/interpolation"
+)
+
+// Style determines the shape data points are drawn as
+type Style string
+
+const (
+	// StyleBars draws a bar for each data point, growing outwards from the inner radius
+	StyleBars Style = "bars"
+	// StyleLine draws a line through all data points, optionally smoothed by interpolation
+	StyleLine Style = "line"
+	// StyleEmpty is used for catching uninitialized styles
+	StyleEmpty Style = ""
+)
+
+var Styles = []string{"bars", "line"}
+
+type Interpolation string
+
+const (
+	// InterpolationNone means no interpolated points and a piecewise linear curve as the result
+	InterpolationNone Interpolation = "none"
+	// InterpolationFritschCarlson applies the Fritsch-Carlson method for
+	// interpolating hermitic cubic splines to fit the data points
+	InterpolationFritschCarlson Interpolation = "fritsch-carlson"
+	// InterpolationSteffen applies the Steffen method for interpolating
+	// hermetic cubic splices to fit the data points.
+	InterpolationSteffen Interpolation = "steffen"
+	// InterpolationAkimaSpline applies Akima's method for interpolating splines
+	InterpolationAkimaSpline Interpolation = "akima"
+	// InterpolationEmpty is for catching uninitialized interpolation modes
+	InterpolationEmpty Interpolation = ""
+)
+
+var Interpolations = []string{"fritsch-carlson", "none", "steffen", "akima"}
+
+const (
+	DefaultBarTemplate  string = `<path d="{{.Path}}" fill="{{.Color}}" />`
+	DefaultLineTemplate string = `<path d="{{.Path}}" stroke="{{.Color}}" stroke-width="{{.StrokeWidth}}" fill="{{.Fill}}" />`
+)
+
+const (
+	// DefaultStyle draws bars
+	DefaultStyle = StyleBars
+	// DefaultInterpolation of the line style
+	DefaultInterpolation = InterpolationFritschCarlson
+	// DefaultColor of bars and lines is black
+	DefaultColor = "black"
+	// DefaultFill of the area enclosed by the line style is transparent
+	DefaultFill = "none"
+	// DefaultStrokeWidth of the line style is 2px
+	DefaultStrokeWidth = float64(2)
+	// DefaultInnerRadius of the circle data points grow out of is 50px
+	DefaultInnerRadius = float64(50)
+	// DefaultAmplitude is the length of a bar of a data point of 1
+	DefaultAmplitude = float64(100)
+	// DefaultStartAngle is at the top of the circle
+	DefaultStartAngle = float64(0)
+	// DefaultSweepAngle is the full circle
+	DefaultSweepAngle = float64(360)
+	// DefaultGap is the share of each bar's angular span left empty
+	DefaultGap = float64(0.2)
+
+	// segmentSteps is the number of line segments each cubic segment of the smooth line
+	// style is approximated with
+	segmentSteps = 8
+)
+
+// Compile-time type checking for RadialPainter to implement all functions required
+// by the Painter interface
+var _ painter.Painter = &RadialPainter{}
+
+type RadialOptions struct {
+	// Style determines whether data points are drawn as bars or as a line
+	Style Style
+	// Interpolation choses the point interpolation mode of the line style
+	Interpolation Interpolation
+	// Color is the fill color of bars and the stroke color of lines, or a fill specification,
+	// see package fill. Color stops map each bar's sample to its color.
+	Color string
+	// Fill is the color of the area enclosed by the line style, or a fill specification
+	Fill string
+	// StrokeWidth is the width of the line style's stroke
+	StrokeWidth float64
+	// InnerRadius is the radius of the circle data points grow outwards from
+	InnerRadius float64
+	// Amplitude is the radial scaling factor by which all data points are scaled up, such
+	// that the canvas's size is twice the sum of inner radius and amplitude
+	Amplitude float64
+	// StartAngle is the angle of the first data point in degrees, clockwise from the top
+	StartAngle float64
+	// SweepAngle is the angle all data points span in degrees
+	SweepAngle float64
+	// Gap is the share of each bar's angular span left empty, in [0,1)
+	Gap float64
+}
+
+// RadialPainter draws data points wrapped around a circle, i.e., in polar coordinates with
+// the data point's index as the angle and its value as the radius
+type RadialPainter struct {
+	// Embed all painter options, i.e., data points
+	*painter.PainterOptions
+	// Embed all options for the radial painter
+	*RadialOptions
+	// fills collects the fills of the last call to Draw
+	fills *fill.Set
+}
+
+// NewPainter constructs a new Radial painter with the passed options and fills in defaults
+// for missing fields
+func NewPainter(painter *painter.PainterOptions, options *RadialOptions) *RadialPainter {
+	if options.Style == StyleEmpty {
+		options.Style = DefaultStyle
+	}
+	if options.Interpolation == InterpolationEmpty {
+		options.Interpolation = DefaultInterpolation
+	}
+	if options.Color == "" {
+		options.Color = DefaultColor
+	}
+	if options.Fill == "" {
+		options.Fill = DefaultFill
+	}
+	if options.Amplitude == 0 {
+		options.Amplitude = DefaultAmplitude
+	}
+	if options.SweepAngle == 0 {
+		options.SweepAngle = DefaultSweepAngle
+	}
+	return &RadialPainter{
+		PainterOptions: painter,
+		RadialOptions:  options,
+	}
+}
+
+// Height returns the canvas's total height, which is the diameter of the outermost circle
+func (r *RadialPainter) Height() float64 {
+	return 2 * (r.InnerRadius + r.Amplitude)
+}
+
+// Width returns the canvas's total width, which equals its height
+func (r *RadialPainter) Width() float64 {
+	return r.Height()
+}
+
+// point converts polar coordinates with the angle in degrees clockwise from the top to
+// cartesian coordinates relative to the canvas's top-left corner
+func (r *RadialPainter) point(angle float64, radius float64) (float64, float64) {
+	center := r.InnerRadius + r.Amplitude
+	rad := angle * math.Pi / 180
+	return center + radius*math.Sin(rad), center - radius*math.Cos(rad)
+}
+
+// angle returns the angle of the center of the data point at index in degrees
+func (r *RadialPainter) angle(index float64) float64 {
+	return r.StartAngle + r.SweepAngle*(index+0.5)/float64(len(r.Data))
+}
+
+// closed reports whether the data points span the full circle, in which case lines connect
+// the last data point to the first one
+func (r *RadialPainter) closed() bool {
+	return math.Abs(r.SweepAngle) >= 360
+}
+
+// Draw implements the Painter interface's Draw function
+func (r *RadialPainter) Draw() []string {
+	output := &strings.Builder{}
+	r.fills = fill.NewSet(r.IDPrefix("radial"))
+	output.WriteString("<g>")
+	if len(r.Data) > 0 {
+		switch r.Style {
+		case StyleLine:
+			r.drawLine(output)
+		default:
+			r.drawBars(output)
+		}
+	}
+	output.WriteString("</g>")
+	return []string{output.String()}
+}
+
+// drawBars draws each data point as a quadrilateral spanning its share of the sweep angle
+// without the gap, from the inner radius outwards
+func (r *RadialPainter) drawBars(output *strings.Builder) {
+	barTemplate := template.New("bar")
+	barTemplate.Parse(DefaultBarTemplate)
+
+	paint := r.fills.Add(r.Color)
+	span := r.SweepAngle / float64(len(r.Data)) * (1 - r.Gap)
+	for index, sample := range r.Data {
+		center := r.angle(float64(index))
+		outer := r.InnerRadius + sample*r.Amplitude
+		path := &strings.Builder{}
+		for i, corner := range [][2]float64{
+			{center - span/2, r.InnerRadius},
+			{center - span/2, outer},
+			{center + span/2, outer},
+			{center + span/2, r.InnerRadius},
+		} {
+			x, y := r.point(corner[0], corner[1])
+			command := "L"
+			if i == 0 {
+				command = "M"
+			}
+			fmt.Fprintf(path, "%s %f %f ", command, x, y)
+		}
+		path.WriteString("Z")
+		barTemplate.Execute(output, struct {
+			Path  string
+			Color string
+		}{path.String(), paint.Paint(sample)})
+	}
+}
+
+// drawLine draws a line through all data points. Interpolation happens in polar coordinates,
+// i.e., on the radius as a function of the angle, and the resulting cubic segments are
+// approximated by straight segments in cartesian coordinates.
+func (r *RadialPainter) drawLine(output *strings.Builder) {
+	lineTemplate := template.New("line")
+	lineTemplate.Parse(DefaultLineTemplate)
+
+	samples := make([][2]float64, 0, len(r.Data)+1)
+	for index, sample := range r.Data {
+		samples = append(samples, [2]float64{r.angle(float64(index)), r.InnerRadius + sample*r.Amplitude})
+	}
+	if r.closed() {
+		// wrap around to the first data point
+		samples = append(samples, [2]float64{r.angle(float64(len(r.Data))), samples[0][1]})
+	}
+
+	polar := samples
+	if interpolator := r.interpolator(); interpolator != nil && len(samples) > 2 {
+		interpolator.Interpolate(samples)
+		polar = [][2]float64{samples[0]}
+		previous := samples[0]
+		for _, segment := range interpolator.Points() {
+			for step := 1; step <= segmentSteps; step++ {
+				polar = append(polar, bezier(previous, segment, float64(step)/segmentSteps))
+			}
+			previous = segment.Root
+		}
+	}
+
+	path := &strings.Builder{}
+	for i, p := range polar {
+		x, y := r.point(p[0], p[1])
+		command := "L"
+		if i == 0 {
+			command = "M"
+		}
+		fmt.Fprintf(path, "%s %f %f ", command, x, y)
+	}
+	if r.closed() {
+		path.WriteString("Z")
+	}
+	peak := fill.Max(r.Data)
+	lineTemplate.Execute(output, struct {
+		Path        string
+		Color       string
+		StrokeWidth float64
+		Fill        string
+	}{path.String(), r.fills.Add(r.Color).Paint(peak), r.StrokeWidth, r.fills.Add(r.Fill).Paint(peak)})
+}
+
+func (r *RadialPainter) interpolator() interpolation.Interpolator {
+	switch r.Interpolation {
+	case InterpolationSteffen:
+		return &interpolation.Steffen{}
+	case InterpolationFritschCarlson:
+		return &interpolation.FritschCarlson{}
+	case InterpolationAkimaSpline:
+		return &interpolation.AkimaSpline{}
+	}
+	return nil
+}
+
+// bezier evaluates the cubic bezier curve from start to the segment's root at t in [0,1]
+func bezier(start [2]float64, segment interpolation.CubicCurvePoint, t float64) [2]float64 {
+	u := 1 - t
+	var p [2]float64
+	for i := range p {
+		p[i] = u*u*u*start[i] + 3*u*u*t*segment.C1[i] + 3*u*t*t*segment.C2[i] + t*t*t*segment.Root[i]
+	}
+	return p
+}
+
+// Defs implements the painter.DefsPainter interface and returns the gradients referenced by
+// the bars or the line
+func (r *RadialPainter) Defs() []string {
+	if r.fills == nil {
+		return nil
+	}
+	return r.fills.Defs(r.Width(), r.Height())
+}
+
+func (r *RadialPainter) Viewbox() string {
+	return fmt.Sprintf("0 0 %f %f", r.Width(), r.Height())
+}
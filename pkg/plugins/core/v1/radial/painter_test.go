@@ -0,0 +1,101 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package radial
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/zoomoid/waveman2/pkg/painter"
+)
+
+var pointPattern = regexp.MustCompile(`[ML] (-?[0-9.]+) (-?[0-9.]+)`)
+
+// points returns the coordinates of all move and line commands of the paths in svg
+func points(t *testing.T, svg string) [][2]float64 {
+	var out [][2]float64
+	for _, m := range pointPattern.FindAllStringSubmatch(svg, -1) {
+		x, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		y, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, [2]float64{x, y})
+	}
+	return out
+}
+
+func TestDrawBars(t *testing.T) {
+	data := []float64{0.2, 1, 0.5, 0.3, 0, 0.8}
+	r := NewPainter(&painter.PainterOptions{
+		Data: data,
+	}, &RadialOptions{
+		InnerRadius: DefaultInnerRadius,
+		Gap:         DefaultGap,
+	})
+
+	svg := strings.Join(r.Draw(), "")
+	if n := strings.Count(svg, "<path"); n != len(data) {
+		t.Errorf("expected %d bars, found %d", len(data), n)
+	}
+	center := r.InnerRadius + r.Amplitude
+	outermost := 0.0
+	for _, p := range points(t, svg) {
+		radius := math.Hypot(p[0]-center, p[1]-center)
+		if radius < r.InnerRadius-1e-3 || radius > center+1e-3 {
+			t.Errorf("expected point %v within the ring [%g,%g], found radius %g", p, r.InnerRadius, center, radius)
+		}
+		outermost = math.Max(outermost, radius)
+	}
+	// the bar of the peak reaches the edge of the canvas
+	if math.Abs(outermost-center) > 1e-3 {
+		t.Errorf("expected outermost radius %g, found %g", center, outermost)
+	}
+}
+
+func TestDrawLine(t *testing.T) {
+	data := []float64{0.2, 1, 0.5, 0.3, 0, 0.8}
+	for _, sweep := range []float64{360, 180} {
+		r := NewPainter(&painter.PainterOptions{
+			Data: data,
+		}, &RadialOptions{
+			Style:       StyleLine,
+			InnerRadius: DefaultInnerRadius,
+			SweepAngle:  sweep,
+		})
+
+		svg := strings.Join(r.Draw(), "")
+		if n := strings.Count(svg, "<path"); n != 1 {
+			t.Errorf("sweep %g: expected a single path, found %d", sweep, n)
+		}
+		for _, p := range points(t, svg) {
+			if p[0] < 0 || p[0] > r.Width() || p[1] < 0 || p[1] > r.Height() {
+				t.Errorf("sweep %g: expected point %v within the viewBox %s", sweep, p, r.Viewbox())
+			}
+		}
+		// only lines around the full circle are closed
+		if closed := strings.Contains(svg, "Z"); closed != (sweep == 360) {
+			t.Errorf("sweep %g: expected closed to be %t, found %s", sweep, sweep == 360, svg)
+		}
+	}
+}
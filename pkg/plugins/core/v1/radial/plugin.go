@@ -0,0 +1,175 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package radial
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/zoomoid/waveman2/pkg/painter"
+	"github.com/zoomoid/waveman2/pkg/plugin"
+	"github.com/zoomoid/waveman2/pkg/utils"
+)
+
+var _ plugin.Plugin = &RadialPlugin{}
+
+var Plugin plugin.Plugin = &RadialPlugin{
+	data: newRadialData(),
+}
+
+type RadialPlugin struct {
+	data    *radialData
+	painter *RadialPainter
+}
+
+func (r *RadialPlugin) Group() string {
+	return group
+}
+
+func (r *RadialPlugin) Name() string {
+	return "radial"
+}
+
+func (r *RadialPlugin) Description() string {
+	return description
+}
+
+func (r *RadialPlugin) Data() interface{} {
+	return r.data
+}
+
+func (r *RadialPlugin) Validate() error {
+	errs := r.data.validateRadialOptions()
+	errlist := utils.NewErrorList(errs)
+	if errlist == nil {
+		return nil
+	}
+	return errors.New(errlist.Error())
+}
+
+func (r *RadialPlugin) Flags(flags *pflag.FlagSet) error {
+	data, ok := r.Data().(*radialData)
+	if !ok {
+		return errors.New("radial data struct is malformed")
+	}
+	flags.StringVar(&data.style, "style", string(DefaultStyle), "Shape of the data points [bars,line]")
+	flags.StringVar(&data.interpolation, "interpolation", string(DefaultInterpolation), "Interpolation mechanism to be used for smoothing the line style [none,fritsch-carlson,steffen,akima]")
+	flags.StringVar(&data.color, "color", DefaultColor, "Color, gradient, or color stops of the bars or the line's stroke")
+	flags.StringVar(&data.fill, "fill-color", DefaultFill, "Color or gradient for the area enclosed by the line style")
+	flags.Float64Var(&data.strokeWidth, "stroke-width", DefaultStrokeWidth, "Width of the line style's stroke")
+	flags.Float64Var(&data.innerRadius, "inner-radius", DefaultInnerRadius, "Radius of the circle data points grow outwards from")
+	flags.Float64Var(&data.startAngle, "start-angle", DefaultStartAngle, "Angle of the first data point in degrees, clockwise from the top")
+	flags.Float64Var(&data.sweepAngle, "sweep-angle", DefaultSweepAngle, "Angle covered by all data points in degrees")
+	flags.Float64Var(&data.gap, "gap", DefaultGap, "Share of each bar's angular span left empty, in [0,1)")
+	return nil
+}
+
+func (r *RadialPlugin) Completions(cmd *cobra.Command) {
+	cmd.RegisterFlagCompletionFunc("style", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return Styles, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.RegisterFlagCompletionFunc("interpolation", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return Interpolations, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.RegisterFlagCompletionFunc("color", cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc("fill-color", cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc("stroke-width", cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc("inner-radius", cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc("start-angle", cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc("sweep-angle", cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc("gap", cobra.NoFileCompletions)
+}
+
+func (r *RadialPlugin) Draw(options *painter.PainterOptions) []string {
+	painter := NewPainter(options, r.data.toOptions(options.Width, options.Height))
+	r.painter = painter
+	return painter.Draw()
+}
+
+func (r *RadialPlugin) Painter() painter.Painter {
+	return r.painter
+}
+
+func (r *radialData) toOptions(width float64, height float64) *RadialOptions {
+	return &RadialOptions{
+		Style:         Style(r.style),
+		Interpolation: Interpolation(r.interpolation),
+		Color:         r.color,
+		Fill:          r.fill,
+		StrokeWidth:   r.strokeWidth,
+		InnerRadius:   r.innerRadius,
+		Amplitude:     height,
+		StartAngle:    r.startAngle,
+		SweepAngle:    r.sweepAngle,
+		Gap:           r.gap,
+	}
+}
+
+func (r *radialData) validateRadialOptions() (errList []error) {
+	if err := validateStyle(r.style); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validateInterpolation(r.interpolation); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validateInnerRadius(r.innerRadius); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validateSweepAngle(r.sweepAngle); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validateGap(r.gap); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validateFill("color", r.color); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validateFill("fill-color", r.fill); err != nil {
+		errList = append(errList, err)
+	}
+	if len(errList) == 0 {
+		return nil
+	}
+	return errList
+}
+
+type radialData struct {
+	style         string
+	interpolation string
+	color         string
+	fill          string
+	strokeWidth   float64
+	innerRadius   float64
+	startAngle    float64
+	sweepAngle    float64
+	gap           float64
+}
+
+func newRadialData() *radialData {
+	return &radialData{
+		style:         string(DefaultStyle),
+		interpolation: string(DefaultInterpolation),
+		color:         DefaultColor,
+		fill:          DefaultFill,
+		strokeWidth:   DefaultStrokeWidth,
+		innerRadius:   DefaultInnerRadius,
+		startAngle:    DefaultStartAngle,
+		sweepAngle:    DefaultSweepAngle,
+		gap:           DefaultGap,
+	}
+}
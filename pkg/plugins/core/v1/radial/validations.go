@@ -0,0 +1,76 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package radial
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zoomoid/waveman2/pkg/painter/fill"
+)
+
+func validateStyle(style string) error {
+	s := Style(style)
+	switch s {
+	case StyleBars,
+		StyleLine,
+		StyleEmpty:
+		return nil
+	}
+	return fmt.Errorf("style %s is not supported", style)
+}
+
+func validateInterpolation(interpolation string) error {
+	i := Interpolation(interpolation)
+	switch i {
+	case InterpolationFritschCarlson,
+		InterpolationSteffen,
+		InterpolationNone,
+		InterpolationEmpty,
+		InterpolationAkimaSpline:
+		return nil
+	}
+	return fmt.Errorf("interpolation %s is not supported", interpolation)
+}
+
+func validateInnerRadius(radius float64) error {
+	if radius < 0 {
+		return errors.New("--inner-radius must be non-negative")
+	}
+	return nil
+}
+
+func validateSweepAngle(angle float64) error {
+	if angle <= 0 || angle > 360 {
+		return errors.New("--sweep-angle must be in (0,360]")
+	}
+	return nil
+}
+
+func validateGap(gap float64) error {
+	if gap < 0 || gap >= 1 {
+		return errors.New("--gap must be in [0,1)")
+	}
+	return nil
+}
+
+func validateFill(flag string, spec string) error {
+	if err := fill.Validate(spec); err != nil {
+		return fmt.Errorf("--%s: %w", flag, err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spectrogram
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/zoomoid/waveman2/pkg/painter/fill"
+)
+
+// ColorMap maps levels in [0,1] to colors. Besides the named color maps, a color map can be
+// given as color stops of hex colors, e.g., "stops(#000, #f00 30%, #ff0)", see package fill
+type ColorMap string
+
+const (
+	ColorMapViridis   ColorMap = "viridis"
+	ColorMapMagma     ColorMap = "magma"
+	ColorMapInferno   ColorMap = "inferno"
+	ColorMapGrayscale ColorMap = "grayscale"
+	// ColorMapEmpty is used for catching uninitialized color maps
+	ColorMapEmpty ColorMap = ""
+)
+
+var ColorMaps = []string{"viridis", "magma", "inferno", "grayscale"}
+
+// colorStops contains the colors of each color map at evenly spaced levels, between which
+// colors are interpolated linearly
+var colorStops = map[ColorMap][]color.NRGBA{
+	ColorMapViridis: {
+		{0x44, 0x01, 0x54, 0xff},
+		{0x3b, 0x52, 0x8b, 0xff},
+		{0x21, 0x91, 0x8c, 0xff},
+		{0x5e, 0xc9, 0x62, 0xff},
+		{0xfd, 0xe7, 0x25, 0xff},
+	},
+	ColorMapMagma: {
+		{0x00, 0x00, 0x04, 0xff},
+		{0x3b, 0x0f, 0x70, 0xff},
+		{0x8c, 0x29, 0x81, 0xff},
+		{0xde, 0x49, 0x68, 0xff},
+		{0xfe, 0x9f, 0x6d, 0xff},
+		{0xfc, 0xfd, 0xbf, 0xff},
+	},
+	ColorMapInferno: {
+		{0x00, 0x00, 0x04, 0xff},
+		{0x42, 0x0a, 0x68, 0xff},
+		{0x93, 0x26, 0x67, 0xff},
+		{0xdd, 0x51, 0x3a, 0xff},
+		{0xfc, 0xa5, 0x0a, 0xff},
+		{0xfc, 0xff, 0xa4, 0xff},
+	},
+	ColorMapGrayscale: {
+		{0x00, 0x00, 0x00, 0xff},
+		{0xff, 0xff, 0xff, 0xff},
+	},
+}
+
+// stops parses a color map given as color stops, and returns false for all other color maps
+func (m ColorMap) stops() (*fill.Fill, bool) {
+	f, err := fill.Parse("", string(m))
+	if err != nil || f.Kind != fill.KindStops {
+		return nil, false
+	}
+	return f, true
+}
+
+// color returns the color of a level, which is clamped to [0,1]
+func (m ColorMap) color(level float64) color.NRGBA {
+	stops, ok := colorStops[m]
+	if f, custom := m.stops(); !ok && custom {
+		rgb, _ := fill.ParseHexColor(f.Paint(math.Max(0, math.Min(1, level))))
+		return color.NRGBA{R: uint8(rgb[0]), G: uint8(rgb[1]), B: uint8(rgb[2]), A: 0xff}
+	}
+	if !ok {
+		stops = colorStops[DefaultColorMap]
+	}
+	position := math.Max(0, math.Min(1, level)) * float64(len(stops)-1)
+	i := int(position)
+	if i >= len(stops)-1 {
+		return stops[len(stops)-1]
+	}
+	t := position - float64(i)
+	lerp := func(a, b uint8) uint8 {
+		return uint8(math.Round((1-t)*float64(a) + t*float64(b)))
+	}
+	return color.NRGBA{
+		R: lerp(stops[i].R, stops[i+1].R),
+		G: lerp(stops[i].G, stops[i+1].G),
+		B: lerp(stops[i].B, stops[i+1].B),
+		A: 0xff,
+	}
+}
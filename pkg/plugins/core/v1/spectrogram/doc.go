@@ -0,0 +1,46 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spectrogram
+
+import "github.com/lithammer/dedent"
+
+var (
+	group string = "core/v1"
+
+	description string = dedent.Dedent(`
+		The spectrogram painter draws the frequency content of the audio over time. Each
+		chunk is a column, and each frequency band a row, colored by its level.
+
+		The spectrum is computed with a short-time Fourier transform of --fft-size samples
+		per frame, which must be a power of two, and --hop samples between frames, half
+		of the FFT size by default. Frames are windowed with --fft-window, "hann" by
+		default, which accepts the same algorithms as --window. Larger FFT sizes resolve
+		frequencies more finely, at the cost of time resolution.
+
+		--bins sets the number of rows, which are spaced by --frequency-scale, either
+		"linear", "log", or "mel". Each row's level is the peak of all frames in the
+		chunk, mapped from --floor, -90 dBFS by default, to full scale.
+
+		--color-map determines the colors of levels, one of "viridis", "magma",
+		"inferno", or "grayscale". With --render rects, each cell is an SVG rect,
+		with --render raster, the spectrogram is embedded as a single PNG image, which
+		keeps the SVG small for high resolutions.
+
+		--width (or -w) sets the width of each column, --height (or -y) the height of
+		the entire spectrogram.
+	`)
+)
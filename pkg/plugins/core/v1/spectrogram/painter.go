@@ -0,0 +1,205 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spectrogram
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+	"text/template"
+
+	"github.com/zoomoid/waveman2/pkg/painter"
+)
+
+// Render determines how the cells of a spectrogram are drawn
+type Render string
+
+const (
+	// RenderRects draws each cell as an SVG rect
+	RenderRects Render = "rects"
+	// RenderRaster embeds the spectrogram as a PNG image with a pixel for each cell
+	RenderRaster Render = "raster"
+	// RenderEmpty is used for catching uninitialized render modes
+	RenderEmpty Render = ""
+)
+
+var Renders = []string{"rects", "raster"}
+
+const (
+	DefaultCellTemplate  string = `<rect width="{{.Width}}" height="{{.Height}}" x="{{.X}}" y="{{.Y}}" fill="{{.Color}}" />`
+	DefaultImageTemplate string = `<image width="{{.Width}}" height="{{.Height}}" x="0" y="0" preserveAspectRatio="none" style="image-rendering: pixelated" xlink:href="data:image/png;base64,{{.Data}}" />`
+)
+
+const (
+	// DefaultColorMap is viridis
+	DefaultColorMap = ColorMapViridis
+	// DefaultRender draws rects
+	DefaultRender = RenderRects
+	// DefaultHeight of a canvas is 200px
+	DefaultHeight = float64(200)
+	// DefaultColumnWidth of each column is 10px
+	DefaultColumnWidth = float64(10)
+)
+
+// Compile-time type checking for SpectrogramPainter to implement all functions required
+// by the Painter interface
+var _ painter.Painter = &SpectrogramPainter{}
+
+type SpectrogramOptions struct {
+	// ColorMap maps the level of each cell to its color
+	ColorMap ColorMap
+	// Render determines whether cells are drawn as rects or as an embedded raster image
+	Render Render
+	// ColumnWidth is the width of each column, i.e., each chunk
+	ColumnWidth float64
+	// Height is the total height of the canvas, which is divided evenly among the rows
+	Height float64
+}
+
+// SpectrogramPainter draws the spectrogram of the transformer as a grid of colored cells, with
+// time on the horizontal and frequency on the vertical axis, low frequencies at the bottom
+type SpectrogramPainter struct {
+	// Embed all painter options, i.e., the spectrogram
+	*painter.PainterOptions
+	// Embed all options for the spectrogram painter
+	*SpectrogramOptions
+}
+
+// NewPainter constructs a new Spectrogram painter with the passed options and fills in
+// defaults for missing fields
+func NewPainter(painter *painter.PainterOptions, options *SpectrogramOptions) *SpectrogramPainter {
+	if options.ColorMap == ColorMapEmpty {
+		options.ColorMap = DefaultColorMap
+	}
+	if options.Render == RenderEmpty {
+		options.Render = DefaultRender
+	}
+	if options.ColumnWidth == 0 {
+		options.ColumnWidth = DefaultColumnWidth
+	}
+	if options.Height == 0 {
+		options.Height = DefaultHeight
+	}
+	return &SpectrogramPainter{
+		PainterOptions:     painter,
+		SpectrogramOptions: options,
+	}
+}
+
+// Height returns the canvas's total height
+func (s *SpectrogramPainter) Height() float64 {
+	return s.SpectrogramOptions.Height
+}
+
+// Width returns the canvas's total width, which is the number of columns times the width of
+// each column
+func (s *SpectrogramPainter) Width() float64 {
+	return float64(s.columns()) * s.ColumnWidth
+}
+
+func (s *SpectrogramPainter) columns() int {
+	if s.Spectrogram == nil {
+		return 0
+	}
+	return len(s.Spectrogram.Columns)
+}
+
+func (s *SpectrogramPainter) rows() int {
+	if s.columns() == 0 {
+		return 0
+	}
+	return len(s.Spectrogram.Columns[0])
+}
+
+type cell struct {
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+	Color  string
+}
+
+// Draw implements the Painter interface's Draw function. Without a spectrogram, e.g., when
+// the transformer did not compute one, Draw returns an empty group.
+func (s *SpectrogramPainter) Draw() []string {
+	output := &strings.Builder{}
+	output.WriteString("<g>")
+	if s.rows() > 0 {
+		switch s.Render {
+		case RenderRaster:
+			s.drawRaster(output)
+		default:
+			s.drawRects(output)
+		}
+	}
+	output.WriteString("</g>")
+	return []string{output.String()}
+}
+
+// drawRects draws each cell as a rect
+func (s *SpectrogramPainter) drawRects(output *strings.Builder) {
+	cellTemplate := template.New("cell")
+	cellTemplate.Parse(DefaultCellTemplate)
+
+	rowHeight := s.SpectrogramOptions.Height / float64(s.rows())
+	for x, column := range s.Spectrogram.Columns {
+		for y, level := range column {
+			c := s.ColorMap.color(level)
+			cellTemplate.Execute(output, &cell{
+				X:      float64(x) * s.ColumnWidth,
+				Y:      float64(len(column)-1-y) * rowHeight,
+				Width:  s.ColumnWidth,
+				Height: rowHeight,
+				Color:  fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B),
+			})
+		}
+	}
+}
+
+// drawRaster embeds the spectrogram as a PNG image with a pixel for each cell, which is
+// scaled to the canvas
+func (s *SpectrogramPainter) drawRaster(output *strings.Builder) {
+	img := image.NewNRGBA(image.Rect(0, 0, s.columns(), s.rows()))
+	for x, column := range s.Spectrogram.Columns {
+		for y, level := range column {
+			img.SetNRGBA(x, len(column)-1-y, s.ColorMap.color(level))
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return
+	}
+
+	imageTemplate := template.New("image")
+	imageTemplate.Parse(DefaultImageTemplate)
+	imageTemplate.Execute(output, struct {
+		Width  float64
+		Height float64
+		Data   string
+	}{
+		Width:  s.Width(),
+		Height: s.SpectrogramOptions.Height,
+		Data:   base64.StdEncoding.EncodeToString(buf.Bytes()),
+	})
+}
+
+func (s *SpectrogramPainter) Viewbox() string {
+	return fmt.Sprintf("0 0 %f %f", s.Width(), s.SpectrogramOptions.Height)
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spectrogram
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image/png"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/zoomoid/waveman2/pkg/painter"
+	"github.com/zoomoid/waveman2/pkg/transform"
+)
+
+// testSpectrogram has 3 columns of 4 rows, with energy in the lowest row of the first column
+// and in the highest row of the last column
+func testSpectrogram() *transform.Spectrogram {
+	return &transform.Spectrogram{
+		Columns: [][]float64{
+			{1, 0, 0, 0},
+			{0.5, 0.5, 0.5, 0.5},
+			{0, 0, 0, 1},
+		},
+		Frequencies: []float64{0, 100, 1000, 5000, 20000},
+	}
+}
+
+func hex(level float64) string {
+	c := DefaultColorMap.color(level)
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func TestDrawRects(t *testing.T) {
+	s := NewPainter(&painter.PainterOptions{
+		Spectrogram: testSpectrogram(),
+	}, &SpectrogramOptions{})
+
+	svg := strings.Join(s.Draw(), "")
+	if n := strings.Count(svg, "<rect"); n != 12 {
+		t.Errorf("expected a rect for each of 12 cells, found %d", n)
+	}
+	if s.Width() != 3*DefaultColumnWidth {
+		t.Errorf("expected width %g, found %g", 3*DefaultColumnWidth, s.Width())
+	}
+	// low frequencies are at the bottom
+	bottom := fmt.Sprintf(`x="0" y="%g" fill="%s"`, 0.75*DefaultHeight, hex(1))
+	if !strings.Contains(svg, bottom) {
+		t.Errorf("expected lowest row of the first column at the bottom, found %s", svg)
+	}
+	top := fmt.Sprintf(`x="%g" y="0" fill="%s"`, 2*DefaultColumnWidth, hex(1))
+	if !strings.Contains(svg, top) {
+		t.Errorf("expected highest row of the last column at the top, found %s", svg)
+	}
+}
+
+func TestDrawRaster(t *testing.T) {
+	s := NewPainter(&painter.PainterOptions{
+		Spectrogram: testSpectrogram(),
+	}, &SpectrogramOptions{
+		Render: RenderRaster,
+	})
+
+	svg := strings.Join(s.Draw(), "")
+	m := regexp.MustCompile(`base64,([^"]+)"`).FindStringSubmatch(svg)
+	if m == nil {
+		t.Fatalf("expected an embedded PNG image, found %s", svg)
+	}
+	data, err := base64.StdEncoding.DecodeString(m[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the image has a pixel for each cell, with low frequencies at the bottom
+	if b := img.Bounds(); b.Dx() != 3 || b.Dy() != 4 {
+		t.Fatalf("expected a 3x4 image, found %dx%d", b.Dx(), b.Dy())
+	}
+	if r, _, _, _ := img.At(0, 3).RGBA(); r>>8 != uint32(DefaultColorMap.color(1).R) {
+		t.Errorf("expected lowest row of the first column at the bottom")
+	}
+	if r, _, _, _ := img.At(2, 0).RGBA(); r>>8 != uint32(DefaultColorMap.color(1).R) {
+		t.Errorf("expected highest row of the last column at the top")
+	}
+}
+
+func TestDrawEmpty(t *testing.T) {
+	// transformers without a spectrogram yield an empty canvas
+	s := NewPainter(&painter.PainterOptions{}, &SpectrogramOptions{})
+	if svg := strings.Join(s.Draw(), ""); svg != "<g></g>" {
+		t.Errorf("expected an empty group, found %s", svg)
+	}
+	if s.Width() != 0 {
+		t.Errorf("expected width 0, found %g", s.Width())
+	}
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spectrogram
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/zoomoid/waveman2/pkg/painter"
+	"github.com/zoomoid/waveman2/pkg/plugin"
+	"github.com/zoomoid/waveman2/pkg/transform"
+	"github.com/zoomoid/waveman2/pkg/utils"
+)
+
+var _ plugin.Plugin = &SpectrogramPlugin{}
+
+var _ plugin.TransformerConfigurer = &SpectrogramPlugin{}
+
+var Plugin plugin.Plugin = &SpectrogramPlugin{
+	data: newSpectrogramData(),
+}
+
+type SpectrogramPlugin struct {
+	data    *spectrogramData
+	painter *SpectrogramPainter
+}
+
+func (s *SpectrogramPlugin) Group() string {
+	return group
+}
+
+func (s *SpectrogramPlugin) Name() string {
+	return "spectrogram"
+}
+
+func (s *SpectrogramPlugin) Description() string {
+	return description
+}
+
+func (s *SpectrogramPlugin) Data() interface{} {
+	return s.data
+}
+
+func (s *SpectrogramPlugin) Validate() error {
+	errs := s.data.validateSpectrogramOptions()
+	errlist := utils.NewErrorList(errs)
+	if errlist == nil {
+		return nil
+	}
+	return errors.New(errlist.Error())
+}
+
+func (s *SpectrogramPlugin) Flags(flags *pflag.FlagSet) error {
+	data, ok := s.Data().(*spectrogramData)
+	if !ok {
+		return errors.New("spectrogram data struct is malformed")
+	}
+	flags.IntVar(&data.fftSize, "fft-size", transform.DefaultFFTSize, "Number of samples of each frame of the short-time Fourier transform. Must be a power of two")
+	flags.IntVar(&data.hop, "hop", 0, "Number of samples between the start of consecutive frames. Defaults to half of --fft-size")
+	flags.StringVar(&data.window, "fft-window", transform.DefaultSpectrogramAlgorithm.String(), "Window algorithm applied to each frame, see --window")
+	flags.Float64Var(&data.windowP, "fft-window-p", transform.DefaultWindowParameter, "Window algorithm parameter of --fft-window, see --window-p")
+	flags.StringVar(&data.frequencyScale, "frequency-scale", string(transform.DefaultFrequencyScale), "Spacing of the rows' frequencies, chose one of 'linear', 'log', or 'mel'")
+	flags.IntVar(&data.bins, "bins", transform.DefaultSpectrogramBins, "Number of rows of the spectrogram")
+	flags.Float64Var(&data.floor, "floor", transform.DefaultSpectrogramFloor, "Lowest level in dBFS, which is drawn with the first color of --color-map")
+	flags.StringVar(&data.colorMap, "color-map", string(DefaultColorMap), "Colors of the levels, chose one of 'viridis', 'magma', 'inferno', or 'grayscale', or give color stops, e.g., 'stops(#000, #f00 30%, #ff0)'")
+	flags.StringVar(&data.render, "render", string(DefaultRender), "Draws each cell as an SVG rect with 'rects', or embeds the spectrogram as a PNG image with 'raster'")
+	return nil
+}
+
+func (s *SpectrogramPlugin) Completions(cmd *cobra.Command) {
+	cmd.RegisterFlagCompletionFunc("fft-size", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"256", "512", "1024", "2048", "4096", "8192"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.RegisterFlagCompletionFunc("hop", cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc("fft-window", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return transform.WindowAlgorithms, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.RegisterFlagCompletionFunc("fft-window-p", cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc("frequency-scale", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return transform.FrequencyScales, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.RegisterFlagCompletionFunc("bins", cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc("floor", cobra.NoFileCompletions)
+	cmd.RegisterFlagCompletionFunc("color-map", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return ColorMaps, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.RegisterFlagCompletionFunc("render", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return Renders, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// ConfigureTransformer requests the spectrogram from the transformer
+func (s *SpectrogramPlugin) ConfigureTransformer(options *transform.ReaderOptions) {
+	options.Spectrogram = &transform.SpectrogramOptions{
+		FFTSize: s.data.fftSize,
+		Hop:     s.data.hop,
+		Window: &transform.Window{
+			Algorithm: transform.WindowAlgorithmFromString(s.data.window),
+			P:         s.data.windowP,
+		},
+		Scale:   transform.FrequencyScale(s.data.frequencyScale),
+		Bins:    s.data.bins,
+		DBFloor: s.data.floor,
+	}
+}
+
+func (s *SpectrogramPlugin) Draw(options *painter.PainterOptions) []string {
+	painter := NewPainter(options, s.data.toOptions(options.Width, options.Height))
+	s.painter = painter
+	return painter.Draw()
+}
+
+func (s *SpectrogramPlugin) Painter() painter.Painter {
+	return s.painter
+}
+
+type spectrogramData struct {
+	fftSize        int
+	hop            int
+	window         string
+	windowP        float64
+	frequencyScale string
+	bins           int
+	floor          float64
+	colorMap       string
+	render         string
+}
+
+func (s *spectrogramData) validateSpectrogramOptions() (errList []error) {
+	if err := validateFFTSize(s.fftSize); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validateHop(s.hop); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validateFrequencyScale(s.frequencyScale); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validateBins(s.bins); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validateFloor(s.floor); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validateColorMap(s.colorMap); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validateRender(s.render); err != nil {
+		errList = append(errList, err)
+	}
+	if len(errList) == 0 {
+		return nil
+	}
+	return errList
+}
+
+func (s *spectrogramData) toOptions(width float64, height float64) *SpectrogramOptions {
+	return &SpectrogramOptions{
+		ColorMap:    ColorMap(s.colorMap),
+		Render:      Render(s.render),
+		ColumnWidth: width,
+		Height:      height,
+	}
+}
+
+func newSpectrogramData() *spectrogramData {
+	return &spectrogramData{
+		fftSize:        transform.DefaultFFTSize,
+		window:         transform.DefaultSpectrogramAlgorithm.String(),
+		frequencyScale: string(transform.DefaultFrequencyScale),
+		bins:           transform.DefaultSpectrogramBins,
+		floor:          transform.DefaultSpectrogramFloor,
+		colorMap:       string(DefaultColorMap),
+		render:         string(DefaultRender),
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spectrogram
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zoomoid/waveman2/pkg/transform"
+)
+
+func validateFFTSize(size int) error {
+	if size < 2 || size&(size-1) != 0 {
+		return fmt.Errorf("--fft-size %d is not a power of two", size)
+	}
+	return nil
+}
+
+func validateHop(hop int) error {
+	if hop < 0 {
+		return errors.New("--hop must be non-negative")
+	}
+	return nil
+}
+
+func validateFrequencyScale(scale string) error {
+	s := transform.FrequencyScale(scale)
+	switch s {
+	case transform.FrequencyLinear,
+		transform.FrequencyLog,
+		transform.FrequencyMel,
+		transform.FrequencyEmpty:
+		return nil
+	}
+	return fmt.Errorf("--frequency-scale %s is not supported", scale)
+}
+
+func validateBins(bins int) error {
+	if bins <= 0 {
+		return errors.New("--bins must be strictly positive")
+	}
+	return nil
+}
+
+func validateFloor(floor float64) error {
+	if floor >= 0 {
+		return errors.New("--floor must be strictly negative")
+	}
+	return nil
+}
+
+func validateColorMap(colorMap string) error {
+	if _, ok := colorStops[ColorMap(colorMap)]; ok || ColorMap(colorMap) == ColorMapEmpty {
+		return nil
+	}
+	if _, ok := ColorMap(colorMap).stops(); ok {
+		return nil
+	}
+	return fmt.Errorf("--color-map %s is not supported", colorMap)
+}
+
+func validateRender(render string) error {
+	r := Render(render)
+	switch r {
+	case RenderRects,
+		RenderRaster,
+		RenderEmpty:
+		return nil
+	}
+	return fmt.Errorf("--render %s is not supported", render)
+}
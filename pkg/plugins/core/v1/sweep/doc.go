@@ -34,6 +34,9 @@ var (
 		The color of the line is set with --stroke-color, and the width of the line 
 		with --stroke-width. All those require SVG/CSS-compliant values for the
 		attributes.
+		
+		Both --fill-color and --stroke-color also accept gradients, e.g.,
+		"linear-gradient(to top, #0a0, #f00)" to color the shape by its height.
 
 		The shape can be horizontally mirrored by setting --inverted (-i).
 
@@ -22,6 +22,7 @@ import (
 	"text/template"
 
 	"github.com/zoomoid/waveman2/pkg/painter"
+	"github.com/zoomoid/waveman2/pkg/painter/fill"
 	"github.com/zoomoid/waveman2/pkg/utils/interpolation"
 )
 
@@ -53,7 +54,8 @@ const (
 )
 
 type Stroke struct {
-	// Color is the CSS-compliant stroke color used for the path
+	// Color is the CSS-compliant stroke color used for the path, or a fill specification,
+	// see package fill
 	Color string
 	// Width is the stroke width used for the path
 	Width float64
@@ -62,7 +64,8 @@ type Stroke struct {
 type LineOptions struct {
 	// Interpolation choses the point interpolation mode
 	Interpolation Interpolation
-	// Fill is the CSS-compliant color value for the fill
+	// Fill is the CSS-compliant color value for the fill, or a fill specification, see
+	// package fill
 	Fill string
 	// Stroke is a struct defining properties of the stroke, namely color and
 	// width
@@ -108,6 +111,8 @@ type SweepPainter struct {
 	*painter.PainterOptions
 	// Embed all options for the line painter
 	*LineOptions
+	// fills collects the fills of the last call to Draw
+	fills *fill.Set
 }
 
 // NewPainter constructs a new Line painter with the passed options and fills in defaults
@@ -173,44 +178,22 @@ func (l *SweepPainter) Draw() []string {
 	pathTemplate.Parse(DefaultPathTemplate)
 	var offset float64 = l.Amplitude / 2.0
 
-	// make a slice of pairs that have the spread x values and their y values paired
-	var samples [][2]float64
-
-	samples = make([][2]float64, 0, len(l.Data)+2)
-
-	// start point
-	samples = append(samples, [2]float64{0, offset})
-	for i, sample := range l.Data {
-		// offset samples in X direction by one unit of spread to account for start points
-		samples = append(samples, [2]float64{
-			float64(i+1) * l.Spread,
-			offset - 0.5*l.Amplitude*sample,
-		})
-	}
-	// end point
-	samples = append(samples, [2]float64{l.Width(), offset})
-
-	var i interpolation.Interpolator
-	switch l.Interpolation {
-	case InterpolationSteffen:
-		i = &interpolation.Steffen{}
-	case InterpolationFritschCarlson:
-		i = &interpolation.FritschCarlson{}
-	case InterpolationAkimaSpline:
-		i = &interpolation.AkimaSpline{}
-	}
+	samples := l.points(l.Data, offset)
 
 	// 1st pass of interpolation: this is all values above the virtual sweep axis (at offset)
-	i.Interpolate(samples)
-
-	abovePoints := i.Points()
+	abovePoints := l.interpolate(samples)
 	belowPoints := make([]interpolation.CubicCurvePoint, len(abovePoints))
 
-	for i, p := range abovePoints {
-		belowPoints[i] = interpolation.CubicCurvePoint{
-			Root: [2]float64{p.Root[0], 2*offset - p.Root[1]},
-			C1:   [2]float64{p.C1[0], 2*offset - p.C1[1]},
-			C2:   [2]float64{p.C2[0], 2*offset - p.C2[1]},
+	if l.LowerEnvelope != nil {
+		// signed envelopes are interpolated independently instead of mirroring the upper curve
+		copy(belowPoints, l.interpolate(l.points(l.LowerEnvelope, offset)))
+	} else {
+		for i, p := range abovePoints {
+			belowPoints[i] = interpolation.CubicCurvePoint{
+				Root: [2]float64{p.Root[0], 2*offset - p.Root[1]},
+				C1:   [2]float64{p.C1[0], 2*offset - p.C1[1]},
+				C2:   [2]float64{p.C2[0], 2*offset - p.C2[1]},
+			}
 		}
 	}
 	n := len(belowPoints) - 1
@@ -245,10 +228,15 @@ func (l *SweepPainter) Draw() []string {
 	// close shape
 	segmentWriter.WriteString("Z")
 
+	l.fills = fill.NewSet(l.IDPrefix("sweep"))
+	peak := fill.Max(l.Data)
 	bindings := templateBindings{
-		Fill:   l.Fill,
-		Path:   segmentWriter.String(),
-		Stroke: l.Stroke,
+		Fill: l.fills.Add(l.Fill).Paint(peak),
+		Path: segmentWriter.String(),
+		Stroke: &Stroke{
+			Color: l.fills.Add(l.Stroke.Color).Paint(peak),
+			Width: l.Stroke.Width,
+		},
 	}
 
 	output.WriteString(`<g style="transform-origin: center center;">`)
@@ -258,6 +246,55 @@ func (l *SweepPainter) Draw() []string {
 	return []string{output.String()}
 }
 
+// points pairs the spread x values of a series of samples with their y values, starting and
+// ending at the sweep axis at offset
+func (l *SweepPainter) points(data []float64, offset float64) [][2]float64 {
+	samples := make([][2]float64, 0, len(data)+2)
+
+	// start point
+	samples = append(samples, [2]float64{0, offset})
+	for i, sample := range data {
+		// offset samples in X direction by one unit of spread to account for start points
+		samples = append(samples, [2]float64{
+			float64(i+1) * l.Spread,
+			offset - 0.5*l.Amplitude*sample,
+		})
+	}
+	// end point
+	samples = append(samples, [2]float64{l.Width(), offset})
+	return samples
+}
+
+// interpolate computes the cubic curve points of samples with the painter's interpolation mode
+func (l *SweepPainter) interpolate(samples [][2]float64) []interpolation.CubicCurvePoint {
+	var i interpolation.Interpolator
+	switch l.Interpolation {
+	case InterpolationSteffen:
+		i = &interpolation.Steffen{}
+	case InterpolationFritschCarlson:
+		i = &interpolation.FritschCarlson{}
+	case InterpolationAkimaSpline:
+		i = &interpolation.AkimaSpline{}
+	}
+	i.Interpolate(samples)
+	return i.Points()
+}
+
+// Defs implements the painter.DefsPainter interface and returns the gradients referenced by
+// the shape's fill and stroke
+func (l *SweepPainter) Defs() []string {
+	if l.fills == nil {
+		return nil
+	}
+	return l.fills.Defs(l.Width(), l.Height())
+}
+
+// Axis implements the painter.AxisPainter interface. The shape encloses the canvas's
+// horizontal center axis.
+func (l *SweepPainter) Axis() float64 {
+	return 0.5 * l.Amplitude
+}
+
 func (l *SweepPainter) Viewbox() string {
 	// calculate the viewBox: we need to offset the viewbox by the stroke width in all directions to not clip it
 	offset := l.Stroke.Width
@@ -68,8 +68,8 @@ func (l *SweepPlugin) Flags(flags *pflag.FlagSet) error {
 		return errors.New("sweep data struct is malformed")
 	}
 	flags.StringVar(&data.interpolation, "interpolation", string(DefaultInterpolation), "Interpolation mechanism to be used for smoothing the curve [none,fritsch-carlson,steffena,akima]")
-	flags.StringVar(&data.fill, "fill-color", DefaultFillColor, "Color for the area enclosed by the line")
-	flags.StringVar(&data.strokeColor, "stroke-color", DefaultStrokeColor, "Color of the line's stroke")
+	flags.StringVar(&data.fill, "fill-color", DefaultFillColor, "Color or gradient for the area enclosed by the line")
+	flags.StringVar(&data.strokeColor, "stroke-color", DefaultStrokeColor, "Color or gradient of the line's stroke")
 	flags.Float64Var(&data.strokeWidth, "stroke-width", DefaultStrokeWidth, "Width of the line's stroke")
 	return nil
 }
@@ -112,6 +112,12 @@ func (l *sweepData) validateLineOptions() (errList []error) {
 	if err := validateInterpolation(l.interpolation); err != nil {
 		errList = append(errList, err)
 	}
+	if err := validateFill("fill-color", l.fill); err != nil {
+		errList = append(errList, err)
+	}
+	if err := validateFill("stroke-color", l.strokeColor); err != nil {
+		errList = append(errList, err)
+	}
 	if len(errList) == 0 {
 		return nil
 	}
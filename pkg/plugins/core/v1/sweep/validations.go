@@ -16,7 +16,11 @@ limitations under the License.
 
 package sweep
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/zoomoid/waveman2/pkg/painter/fill"
+)
 
 func validateInterpolation(interpolation string) error {
 	i := Interpolation(interpolation)
@@ -30,3 +34,10 @@ func validateInterpolation(interpolation string) error {
 	}
 	return fmt.Errorf("interpolation %s is not supported", interpolation)
 }
+
+func validateFill(flag string, spec string) error {
+	if err := fill.Validate(spec); err != nil {
+		return fmt.Errorf("--%s: %w", flag, err)
+	}
+	return nil
+}
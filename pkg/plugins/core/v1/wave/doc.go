@@ -27,7 +27,7 @@ var (
 
 		The color of the line is set with --stroke-color, and the width of the line 
 		with --stroke-width. All those require SVG/CSS-compliant values for the
-		attributes.
+		attributes. A gradient given to --stroke-color spans the entire wave.
 
 		Similarly to the box painter, the --height (or -h) flag controls the shape's overall
 		height. 
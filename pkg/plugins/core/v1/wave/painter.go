@@ -22,6 +22,7 @@ import (
 	"text/template"
 
 	"github.com/zoomoid/waveman2/pkg/painter"
+	"github.com/zoomoid/waveman2/pkg/painter/fill"
 	"github.com/zoomoid/waveman2/pkg/utils/interpolation"
 )
 
@@ -53,7 +54,8 @@ const (
 )
 
 type Stroke struct {
-	// Color is the CSS-compliant stroke color used for the path
+	// Color is the CSS-compliant stroke color used for the path, or a fill specification,
+	// see package fill
 	Color string
 	// Width is the stroke width used for the path
 	Width float64
@@ -101,6 +103,8 @@ type WavePainter struct {
 	*painter.PainterOptions
 	// Embed all options for the line painter
 	*WaveOptions
+	// fills collects the fills of the last call to Draw
+	fills *fill.Set
 }
 
 // NewPainter constructs a new Line painter with the passed options and fills in defaults
@@ -200,9 +204,13 @@ func (l *WavePainter) Draw() []string {
 		line = interpolation.CreateLine(samples, &interpolation.AkimaSpline{})
 	}
 
+	l.fills = fill.NewSet(l.IDPrefix("wave"))
 	bindings := templateBindings{
-		Path:   line,
-		Stroke: l.Stroke,
+		Path: line,
+		Stroke: &Stroke{
+			Color: l.fills.Add(l.Stroke.Color).Paint(fill.Max(l.Data)),
+			Width: l.Stroke.Width,
+		},
 	}
 
 	output.WriteString(`<g style="transform-origin: center center;">`)
@@ -212,6 +220,21 @@ func (l *WavePainter) Draw() []string {
 	return []string{output.String()}
 }
 
+// Defs implements the painter.DefsPainter interface and returns the gradients referenced by
+// the wave's stroke
+func (l *WavePainter) Defs() []string {
+	if l.fills == nil {
+		return nil
+	}
+	return l.fills.Defs(l.Width(), l.Height())
+}
+
+// Axis implements the painter.AxisPainter interface. The wave oscillates around the
+// canvas's horizontal center axis.
+func (l *WavePainter) Axis() float64 {
+	return 0.5 * l.Amplitude
+}
+
 func (l *WavePainter) Viewbox() string {
 	// calculate the viewBox: we need to offset the viewbox by the stroke width in all directions to not clip it
 	offset := l.Stroke.Width
@@ -68,7 +68,7 @@ func (l *WavePlugin) Flags(flags *pflag.FlagSet) error {
 		return errors.New("wave data struct is malformed")
 	}
 	flags.StringVar(&data.interpolation, "interpolation", string(DefaultInterpolation), "Interpolation mechanism to be used for smoothing the curve [none,fritsch-carlson,steffen,akima]")
-	flags.StringVar(&data.strokeColor, "stroke-color", DefaultStrokeColor, "Color of the line's stroke")
+	flags.StringVar(&data.strokeColor, "stroke-color", DefaultStrokeColor, "Color or gradient of the line's stroke")
 	flags.Float64Var(&data.strokeWidth, "stroke-width", DefaultStrokeWidth, "Width of the line's stroke")
 	return nil
 }
@@ -107,6 +107,9 @@ func (l *waveData) validateLineOptions() (errList []error) {
 	if err := validateInterpolation(l.interpolation); err != nil {
 		errList = append(errList, err)
 	}
+	if err := validateFill("stroke-color", l.strokeColor); err != nil {
+		errList = append(errList, err)
+	}
 	if len(errList) == 0 {
 		return nil
 	}
@@ -35,12 +35,16 @@ func Box(f io.Reader, transformerOptions *transform.ReaderOptions, boxOptions *b
 	blocks := transformer.Blocks()
 
 	boxPainter := box.NewPainter(&painter.PainterOptions{
-		Data: blocks,
+		Data:          blocks,
+		LowerData:     transformer.RightBlocks(),
+		LowerEnvelope: transformer.LowerEnvelope(),
+		Bands:         transformer.Bands(),
+		Metadata:      transformer.Metadata(),
 	}, boxOptions)
 
 	elements := boxPainter.Draw()
 
-	svg, err := svg.Template(elements, true, boxPainter.Viewbox())
+	svg, err := svg.Template(elements, true, boxPainter.Viewbox(), boxPainter.Defs()...)
 	if err != nil {
 		return "", err
 	}
@@ -57,12 +61,16 @@ func Line(f io.Reader, transformerOptions *transform.ReaderOptions, lineOptions
 	blocks := transformer.Blocks()
 
 	linePainter := line.NewPainter(&painter.PainterOptions{
-		Data: blocks,
+		Data:          blocks,
+		LowerData:     transformer.RightBlocks(),
+		LowerEnvelope: transformer.LowerEnvelope(),
+		Bands:         transformer.Bands(),
+		Metadata:      transformer.Metadata(),
 	}, lineOptions)
 
 	elements := linePainter.Draw()
 
-	svg, err := svg.Template(elements, true, linePainter.Viewbox())
+	svg, err := svg.Template(elements, true, linePainter.Viewbox(), linePainter.Defs()...)
 	if err != nil {
 		return "", err
 	}
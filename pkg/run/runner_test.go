@@ -102,13 +102,12 @@ func TestLine(t *testing.T) {
 
 	lineOptions := &line.LineOptions{
 		Interpolation: line.InterpolationSteffen,
-		// Fill:          line.DefaultFillColor,
-		Fill:      "black",
-		Stroke:    nil,
-		Closed:    false,
-		Spread:    25,
-		Amplitude: 100,
-		Inverted:  true,
+		Fill:          "black",
+		Stroke:        nil,
+		Closed:        false,
+		Spread:        25,
+		Amplitude:     100,
+		Inverted:      true,
 	}
 
 	svg, err := Line(fileFactory(), transformerOptions, lineOptions)
@@ -143,12 +142,11 @@ func TestLineMirrored(t *testing.T) {
 
 	lineOptions := &line.LineOptions{
 		Interpolation: line.InterpolationSteffen,
-		// Fill:          line.DefaultFillColor,
-		Fill:      "black",
-		Stroke:    nil,
-		Closed:    false,
-		Spread:    25,
-		Amplitude: 60,
+		Fill:          "black",
+		Stroke:        nil,
+		Closed:        false,
+		Spread:        25,
+		Amplitude:     60,
 	}
 
 	svg, err := Line(fileFactory(), transformerOptions, lineOptions)
@@ -0,0 +1,209 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package svg
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AnimationMode determines how shapes are animated
+type AnimationMode string
+
+const (
+	// AnimationNone disables animations
+	AnimationNone AnimationMode = "none"
+	// AnimationGrow grows each shape from flat to its final shape once
+	AnimationGrow AnimationMode = "grow"
+	// AnimationPulse shrinks each shape to flat and grows it back to its final shape repeatedly
+	AnimationPulse AnimationMode = "pulse"
+	// AnimationEmpty is used for catching uninitialized animation modes
+	AnimationEmpty AnimationMode = ""
+)
+
+var AnimationModes = []string{"none", "grow", "pulse"}
+
+// Easing is the timing function of an animation
+type Easing string
+
+const (
+	EasingLinear    Easing = "linear"
+	EasingEase      Easing = "ease"
+	EasingEaseIn    Easing = "ease-in"
+	EasingEaseOut   Easing = "ease-out"
+	EasingEaseInOut Easing = "ease-in-out"
+	// EasingEmpty is used for catching uninitialized easings
+	EasingEmpty Easing = ""
+)
+
+var Easings = []string{"linear", "ease", "ease-in", "ease-out", "ease-in-out"}
+
+// keySplines contains the cubic bezier control points of each easing, like their CSS
+// counterparts
+var keySplines = map[Easing]string{
+	EasingLinear:    "0 0 1 1",
+	EasingEase:      "0.25 0.1 0.25 1",
+	EasingEaseIn:    "0.42 0 1 1",
+	EasingEaseOut:   "0 0 0.58 1",
+	EasingEaseInOut: "0.42 0 0.58 1",
+}
+
+const (
+	// DefaultAnimationDuration is the duration of the animation of each shape
+	DefaultAnimationDuration = time.Second
+	// DefaultEasing decelerates shapes towards their final shape
+	DefaultEasing = EasingEaseOut
+	// DefaultStagger is the delay between the animations of consecutive shapes
+	DefaultStagger = 20 * time.Millisecond
+)
+
+// AnimationOptions configures the animation of shapes
+type AnimationOptions struct {
+	Mode AnimationMode
+	// Duration is the duration of the animation of a single shape
+	Duration time.Duration
+	// Easing is the timing function of the animation of a single shape
+	Easing Easing
+	// Stagger delays the animation of each shape by its index times Stagger
+	Stagger time.Duration
+	// Axis is the vertical position shapes are flat at, see painter.AxisPainter
+	Axis float64
+}
+
+var (
+	shapePattern     = regexp.MustCompile(`<(rect|path)\b([^>]*?)\s*/>`)
+	attributePattern = regexp.MustCompile(`([\w:-]+)="([^"]*)"`)
+	pathPattern      = regexp.MustCompile(`[A-Za-z]|[-+]?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?`)
+)
+
+// Animate adds SMIL <animate> elements to all rects and paths of the elements, which animate
+// the height of rects and the outline of paths from flat at the axis to their final shape.
+// Shapes keep their final shape as attributes, such that renderers without support for
+// animations show them as is. Shapes whose geometry cannot be flattened are not animated.
+func Animate(elements []string, options *AnimationOptions) []string {
+	if options.Mode == AnimationNone || options.Mode == AnimationEmpty {
+		return elements
+	}
+	index := 0
+	animated := make([]string, len(elements))
+	for i, element := range elements {
+		animated[i] = shapePattern.ReplaceAllStringFunc(element, func(shape string) string {
+			match := shapePattern.FindStringSubmatch(shape)
+			attributes := map[string]string{}
+			for _, attribute := range attributePattern.FindAllStringSubmatch(match[2], -1) {
+				attributes[attribute[1]] = attribute[2]
+			}
+			var animations []string
+			switch match[1] {
+			case "rect":
+				y, errY := strconv.ParseFloat(attributes["y"], 64)
+				height, errHeight := strconv.ParseFloat(attributes["height"], 64)
+				if errY != nil || errHeight != nil {
+					return shape
+				}
+				// rects are flat at the axis, or at their edge closest to it
+				flat := math.Max(y, math.Min(y+height, options.Axis))
+				animations = []string{
+					options.animate(index, "y", strconv.FormatFloat(flat, 'g', -1, 64), attributes["y"]),
+					options.animate(index, "height", "0", attributes["height"]),
+				}
+			case "path":
+				flat, ok := flattenPath(attributes["d"], options.Axis)
+				if !ok {
+					return shape
+				}
+				animations = []string{options.animate(index, "d", flat, strings.TrimSpace(attributes["d"]))}
+			}
+			index++
+			return fmt.Sprintf("<%s%s>%s</%s>", match[1], match[2], strings.Join(animations, ""), match[1])
+		})
+	}
+	return animated
+}
+
+// animate creates an <animate> element for an attribute of the shape at index. Growing
+// animations are delayed within their keyframes instead of by their begin, such that shapes
+// are flat until their animation starts.
+func (o *AnimationOptions) animate(index int, attribute string, flat string, final string) string {
+	duration := o.Duration
+	if duration <= 0 {
+		duration = DefaultAnimationDuration
+	}
+	spline, ok := keySplines[o.Easing]
+	if !ok {
+		spline = keySplines[DefaultEasing]
+	}
+	delay := time.Duration(index) * o.Stagger
+	if o.Mode == AnimationPulse {
+		return fmt.Sprintf(`<animate attributeName="%s" values="%s;%s;%s" keyTimes="0;0.5;1" calcMode="spline" keySplines="%s;%s" dur="%gs" begin="%gs" repeatCount="indefinite" />`,
+			attribute, final, flat, final, spline, spline, duration.Seconds(), delay.Seconds())
+	}
+	if delay == 0 {
+		return fmt.Sprintf(`<animate attributeName="%s" values="%s;%s" keyTimes="0;1" calcMode="spline" keySplines="%s" dur="%gs" begin="0s" fill="freeze" />`,
+			attribute, flat, final, spline, duration.Seconds())
+	}
+	total := delay + duration
+	return fmt.Sprintf(`<animate attributeName="%s" values="%s;%s;%s" keyTimes="0;%g;1" calcMode="spline" keySplines="%s;%s" dur="%gs" begin="0s" fill="freeze" />`,
+		attribute, flat, flat, final, delay.Seconds()/total.Seconds(), keySplines[EasingLinear], spline, total.Seconds())
+}
+
+// flattenPath sets all vertical coordinates of a path to the axis, retaining its commands,
+// such that the path can be interpolated with the original one. Only absolute move, line,
+// and curve commands are supported.
+func flattenPath(d string, axis float64) (string, bool) {
+	tokens := pathPattern.FindAllString(d, -1)
+	if len(tokens) == 0 {
+		return "", false
+	}
+	flat := &strings.Builder{}
+	command := ""
+	parameter := 0
+	for _, token := range tokens {
+		if _, err := strconv.ParseFloat(token, 64); err != nil {
+			command = token
+			parameter = 0
+			switch command {
+			case "M", "L", "C", "S", "Q", "T", "H", "V", "Z", "z":
+			default:
+				return "", false
+			}
+			fmt.Fprintf(flat, "%s ", command)
+			continue
+		}
+		vertical := false
+		switch command {
+		case "V":
+			vertical = true
+		case "M", "L", "C", "S", "Q", "T":
+			vertical = parameter%2 == 1
+		case "H":
+		default:
+			// numbers without a command are malformed
+			return "", false
+		}
+		if vertical {
+			token = strconv.FormatFloat(axis, 'g', -1, 64)
+		}
+		fmt.Fprintf(flat, "%s ", token)
+		parameter++
+	}
+	return strings.TrimSpace(flat.String()), true
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package svg
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFlattenPath(t *testing.T) {
+	flat, ok := flattenPath("M 0 10 C 1 2, 3 4, 5 6 L 7 8 H 9 V 1 Z", 100)
+	if !ok {
+		t.Fatal("expected path to be flattened")
+	}
+	expected := "M 0 100 C 1 100 3 100 5 100 L 7 100 H 9 V 100 Z"
+	if flat != expected {
+		t.Errorf("expected %q, found %q", expected, flat)
+	}
+	if _, ok := flattenPath("m 0 10 l 1 1", 100); ok {
+		t.Error("expected relative commands not to be flattened")
+	}
+}
+
+func TestAnimate(t *testing.T) {
+	elements := []string{`<g><rect width="5" height="20" x="0" y="90" fill="black" /><rect width="5" height="10" x="10" y="0" fill="black" /></g>`}
+	animated := Animate(elements, &AnimationOptions{
+		Mode:     AnimationGrow,
+		Duration: time.Second,
+		Easing:   EasingLinear,
+		Stagger:  time.Second,
+		Axis:     100,
+	})[0]
+	// the first rect grows from the axis immediately
+	if !strings.Contains(animated, `<animate attributeName="y" values="100;90" keyTimes="0;1"`) {
+		t.Errorf("expected first rect to grow from the axis, found %s", animated)
+	}
+	// the second rect does not reach the axis, so it grows from its closest edge after a delay
+	if !strings.Contains(animated, `<animate attributeName="height" values="0;0;10" keyTimes="0;0.5;1"`) {
+		t.Errorf("expected second rect to be delayed, found %s", animated)
+	}
+	if !strings.Contains(animated, `<animate attributeName="y" values="10;10;0"`) {
+		t.Errorf("expected second rect to grow from its lower edge, found %s", animated)
+	}
+	if strings.Count(animated, "</rect>") != 2 {
+		t.Errorf("expected rects to contain their animations, found %s", animated)
+	}
+}
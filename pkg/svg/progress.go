@@ -0,0 +1,113 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package svg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ProgressMode determines how the played part of a progress overlay is driven
+type ProgressMode string
+
+const (
+	// ProgressNone disables the progress overlay
+	ProgressNone ProgressMode = "none"
+	// ProgressCSS drives the played part by the CSS custom property --progress in [0,1], which
+	// web players set on the SVG element as the track progresses
+	ProgressCSS ProgressMode = "css"
+	// ProgressAnimate drives the played part by an SVG <animate> element spanning the track's
+	// duration, which web players can synchronize with SVGSVGElement.setCurrentTime
+	ProgressAnimate ProgressMode = "animate"
+	// ProgressEmpty is used for catching uninitialized progress modes
+	ProgressEmpty ProgressMode = ""
+)
+
+var ProgressModes = []string{"none", "css", "animate"}
+
+const (
+	// DefaultPlayedColor is the color of the played part of a progress overlay
+	DefaultPlayedColor = "#ff5500"
+	// ProgressProperty is the CSS custom property driving the played part in ProgressCSS mode
+	ProgressProperty = "--progress"
+)
+
+var ErrUnknownDuration error = errors.New("progress animation requires the duration of the audio")
+
+// ProgressOptions configures the progress overlay of a canvas
+type ProgressOptions struct {
+	Mode ProgressMode
+	// PlayedColor is the CSS-compliant color all elements of the played part are drawn in
+	PlayedColor string
+	// Duration is the length of the animation in ProgressAnimate mode
+	Duration time.Duration
+	// Width and Height are the dimensions of the canvas, see painter.Painter
+	Width  float64
+	Height float64
+	// ID prefixes the IDs of the clip path and the filter. IDs must be unique across a
+	// document, so SVGs inlined into the same HTML page require distinct IDs.
+	ID string
+}
+
+// Progress splits the elements of a painter into two synchronized layers, an unplayed one
+// showing the elements as is, and a played one on top showing them recolored in the played
+// color. The played layer is clipped to the played part of the canvas from the left, which
+// grows as the track progresses. Progress returns the definitions of the clip path and the
+// recoloring filter for Template's defs and the layers replacing the elements.
+func Progress(elements []string, options *ProgressOptions) ([]string, []string, error) {
+	if options.Mode == ProgressNone || options.Mode == ProgressEmpty {
+		return nil, elements, nil
+	}
+	color := options.PlayedColor
+	if color == "" {
+		color = DefaultPlayedColor
+	}
+
+	var clip string
+	switch options.Mode {
+	case ProgressCSS:
+		// scale the full-width clip rectangle from the canvas's left edge
+		clip = fmt.Sprintf(`<rect x="0" y="0" width="%g" height="%g" style="transform-box: view-box; transform-origin: 0 0; transform: scaleX(var(%s, 0))" />`,
+			options.Width, options.Height, ProgressProperty)
+	case ProgressAnimate:
+		if options.Duration <= 0 {
+			return nil, nil, ErrUnknownDuration
+		}
+		clip = fmt.Sprintf(`<rect x="0" y="0" width="0" height="%g"><animate attributeName="width" from="0" to="%g" dur="%gs" begin="0s" fill="freeze" /></rect>`,
+			options.Height, options.Width, options.Duration.Seconds())
+	default:
+		return nil, nil, fmt.Errorf("progress mode %s is not supported", options.Mode)
+	}
+
+	prefix := "progress"
+	if options.ID != "" {
+		prefix = options.ID + "-progress"
+	}
+	defs := []string{
+		fmt.Sprintf(`<clipPath id="%s-clip">%s</clipPath>`, prefix, clip),
+		// flood the played layer's shapes with the played color, retaining their alpha
+		fmt.Sprintf(`<filter id="%s-played"><feFlood flood-color="%s" /><feComposite in2="SourceGraphic" operator="in" /></filter>`, prefix, color),
+	}
+	body := strings.Join(elements, "")
+	layers := []string{
+		fmt.Sprintf(`<g class="unplayed">%s</g>`, body),
+		fmt.Sprintf(`<g class="played" clip-path="url(#%s-clip)" filter="url(#%s-played)">%s</g>`, prefix, prefix, body),
+	}
+	return defs, layers, nil
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package svg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProgressID(t *testing.T) {
+	elements := []string{`<rect width="5" height="20" x="0" y="0" fill="black" />`}
+	// two tracks inlined into the same page must not share their clip paths
+	var documents []string
+	for _, id := range []string{"track-1", "track-2"} {
+		defs, layers, err := Progress(elements, &ProgressOptions{
+			Mode:   ProgressCSS,
+			Width:  10,
+			Height: 20,
+			ID:     id,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		document := strings.Join(append(defs, layers...), "")
+		for _, expected := range []string{
+			`<clipPath id="` + id + `-progress-clip">`,
+			`<filter id="` + id + `-progress-played">`,
+			`clip-path="url(#` + id + `-progress-clip)" filter="url(#` + id + `-progress-played)"`,
+		} {
+			if !strings.Contains(document, expected) {
+				t.Errorf("expected %s in %s", expected, document)
+			}
+		}
+		documents = append(documents, document)
+	}
+	if strings.Contains(documents[1], "track-1") {
+		t.Errorf("expected IDs of the second track to be distinct, found %s", documents[1])
+	}
+}
@@ -0,0 +1,342 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package svg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/vector"
+)
+
+const (
+	// DefaultDPI is the resolution at which one SVG user unit, i.e., one CSS pixel, maps to
+	// one pixel of a raster image
+	DefaultDPI float64 = 96
+)
+
+var ErrViewBox error = errors.New("svg does not have a valid viewBox")
+
+// RasterOptions configures the rasterization of SVGs
+type RasterOptions struct {
+	// Width and Height are the dimensions of the image in pixels. When only one of them is
+	// given, the other one follows from the viewBox's aspect ratio. When neither is given,
+	// both follow from the viewBox's dimensions at DPI. When the given dimensions do not match
+	// the viewBox's aspect ratio, the drawing is centered, like preserveAspectRatio="meet".
+	Width  int
+	Height int
+	// DPI is the resolution of the image when its dimensions follow from the viewBox, such
+	// that 96 DPI renders one user unit as one pixel, and 192 DPI as two
+	DPI float64
+	// Background is the CSS-compliant color of the image's background, which is transparent
+	// if empty
+	Background string
+}
+
+// PNG executes the default SVG template like Template and rasterizes the result to a PNG
+// image, see Rasterize.
+func PNG(elements []string, preserveAspectRatio bool, viewBox string, options *RasterOptions, defs ...string) (*bytes.Buffer, error) {
+	document, err := Template(elements, preserveAspectRatio, viewBox, defs...)
+	if err != nil {
+		return nil, err
+	}
+	return EncodePNG(document, options)
+}
+
+// EncodePNG rasterizes an SVG document and encodes it as PNG, see Rasterize.
+func EncodePNG(document io.Reader, options *RasterOptions) (*bytes.Buffer, error) {
+	img, err := Rasterize(document, options)
+	if err != nil {
+		return nil, err
+	}
+	out := &bytes.Buffer{}
+	if err := png.Encode(out, img); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Rasterize renders an SVG document to an image. The rasterizer supports the subset of SVG
+// emitted by painters, namely rects, circles, paths, and embedded PNG images, filled and
+// stroked with colors and gradients. Animations are not played, such that shapes are drawn in
+// their final state, and the played layer of progress overlays is omitted.
+func Rasterize(document io.Reader, options *RasterOptions) (*image.NRGBA, error) {
+	if options == nil {
+		options = &RasterOptions{}
+	}
+	decoder := xml.NewDecoder(document)
+	r := &rasterizer{
+		gradients: map[string]*gradient{},
+	}
+	stack := []*state{}
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			attributes := map[string]string{}
+			for _, a := range t.Attr {
+				attributes[a.Name.Local] = a.Value
+			}
+			var parent *state
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			}
+			s := inherit(parent, attributes)
+			stack = append(stack, s)
+			if err := r.start(t.Name.Local, s, parent, attributes, options); err != nil {
+				return nil, err
+			}
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	if r.dst == nil {
+		return nil, ErrViewBox
+	}
+	return r.dst, nil
+}
+
+// state contains the presentation attributes inherited by an element's children
+type state struct {
+	fill          string
+	stroke        string
+	strokeWidth   float64
+	fillOpacity   float64
+	strokeOpacity float64
+	opacity       float64
+	// hidden elements are not drawn, e.g., definitions and clipped layers
+	hidden bool
+	// gradient is the gradient whose stops are defined by the element's children
+	gradient *gradient
+}
+
+// inherit derives the state of an element from its parent's state and its attributes
+func inherit(parent *state, attributes map[string]string) *state {
+	s := &state{fill: "black", stroke: "none", strokeWidth: 1, fillOpacity: 1, strokeOpacity: 1, opacity: 1}
+	if parent != nil {
+		c := *parent
+		c.opacity = parent.opacity
+		c.gradient = nil
+		s = &c
+	}
+	if v, ok := attributes["fill"]; ok {
+		s.fill = v
+	}
+	if v, ok := attributes["stroke"]; ok {
+		s.stroke = v
+	}
+	if v, ok := number(attributes, "stroke-width"); ok {
+		s.strokeWidth = v
+	}
+	if v, ok := number(attributes, "fill-opacity"); ok {
+		s.fillOpacity = v
+	}
+	if v, ok := number(attributes, "stroke-opacity"); ok {
+		s.strokeOpacity = v
+	}
+	if v, ok := number(attributes, "opacity"); ok {
+		s.opacity *= v
+	}
+	return s
+}
+
+// rasterizer draws the elements of an SVG document onto its destination image
+type rasterizer struct {
+	dst *image.NRGBA
+	z   *vector.Rasterizer
+	// scale and offset map user units to pixels
+	scale   float64
+	offsetX float64
+	offsetY float64
+
+	gradients map[string]*gradient
+}
+
+// point maps a point in user units to pixels
+func (r *rasterizer) point(x float64, y float64) [2]float64 {
+	return [2]float64{x*r.scale + r.offsetX, y*r.scale + r.offsetY}
+}
+
+// start handles the start of an element
+func (r *rasterizer) start(name string, s *state, parent *state, attributes map[string]string, options *RasterOptions) error {
+	switch name {
+	case "svg":
+		return r.canvas(attributes, options)
+	case "defs", "clipPath", "filter", "animate", "animateTransform", "animateMotion":
+		s.hidden = true
+		return nil
+	case "linearGradient", "radialGradient":
+		s.hidden = true
+		g := newGradient(name, attributes)
+		r.gradients[attributes["id"]] = g
+		s.gradient = g
+		return nil
+	case "stop":
+		if parent != nil && parent.gradient != nil {
+			parent.gradient.addStop(attributes)
+		}
+		return nil
+	case "g":
+		// layers clipped to a region, such as the played layer of progress overlays, depend
+		// on the state of the player and are omitted
+		if _, ok := attributes["clip-path"]; ok {
+			s.hidden = true
+		}
+		return nil
+	}
+	if s.hidden || r.dst == nil {
+		return nil
+	}
+	switch name {
+	case "rect":
+		r.shape(s, rectangle(attributes, r))
+	case "circle":
+		r.shape(s, circle(attributes, r))
+	case "path":
+		r.shape(s, parsePath(attributes["d"], r))
+	case "image":
+		return r.image(attributes, s)
+	}
+	return nil
+}
+
+// canvas allocates the destination image from the viewBox and the raster options
+func (r *rasterizer) canvas(attributes map[string]string, options *RasterOptions) error {
+	fields := strings.Fields(strings.ReplaceAll(attributes["viewBox"], ",", " "))
+	if len(fields) != 4 {
+		return ErrViewBox
+	}
+	var box [4]float64
+	for i, field := range fields {
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return ErrViewBox
+		}
+		box[i] = v
+	}
+	if box[2] <= 0 || box[3] <= 0 {
+		return ErrViewBox
+	}
+	dpi := options.DPI
+	if dpi <= 0 {
+		dpi = DefaultDPI
+	}
+	width, height := float64(options.Width), float64(options.Height)
+	switch {
+	case width <= 0 && height <= 0:
+		width, height = box[2]*dpi/DefaultDPI, box[3]*dpi/DefaultDPI
+	case width <= 0:
+		width = height * box[2] / box[3]
+	case height <= 0:
+		height = width * box[3] / box[2]
+	}
+	w, h := int(math.Max(1, math.Round(width))), int(math.Max(1, math.Round(height)))
+	r.scale = math.Min(float64(w)/box[2], float64(h)/box[3])
+	r.offsetX = (float64(w)-box[2]*r.scale)/2 - box[0]*r.scale
+	r.offsetY = (float64(h)-box[3]*r.scale)/2 - box[1]*r.scale
+	r.dst = image.NewNRGBA(image.Rect(0, 0, w, h))
+	r.z = vector.NewRasterizer(w, h)
+	if options.Background != "" {
+		if c, ok := parseColor(options.Background); ok {
+			draw.Draw(r.dst, r.dst.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+		}
+	}
+	return nil
+}
+
+// shape fills and strokes the subpaths of a shape
+func (r *rasterizer) shape(s *state, subpaths []subpath) {
+	if len(subpaths) == 0 {
+		return
+	}
+	if src := r.paint(s.fill, s.fillOpacity*s.opacity, subpaths); src != nil {
+		r.z.Reset(r.dst.Bounds().Dx(), r.dst.Bounds().Dy())
+		for _, p := range subpaths {
+			polygon(r.z, p.points)
+		}
+		r.z.Draw(r.dst, r.dst.Bounds(), src, image.Point{})
+	}
+	width := s.strokeWidth * r.scale
+	if width <= 0 {
+		return
+	}
+	if src := r.paint(s.stroke, s.strokeOpacity*s.opacity, subpaths); src != nil {
+		r.z.Reset(r.dst.Bounds().Dx(), r.dst.Bounds().Dy())
+		for _, p := range subpaths {
+			stroke(r.z, p, width)
+		}
+		r.z.Draw(r.dst, r.dst.Bounds(), src, image.Point{})
+	}
+}
+
+// image draws an embedded PNG image scaled to its bounds
+func (r *rasterizer) image(attributes map[string]string, s *state) error {
+	href := attributes["href"]
+	const prefix = "data:image/png;base64,"
+	if !strings.HasPrefix(href, prefix) {
+		return nil
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(href, prefix))
+	if err != nil {
+		return fmt.Errorf("failed to decode embedded image: %w", err)
+	}
+	src, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to decode embedded image: %w", err)
+	}
+	x, _ := number(attributes, "x")
+	y, _ := number(attributes, "y")
+	width, _ := number(attributes, "width")
+	height, _ := number(attributes, "height")
+	min, max := r.point(x, y), r.point(x+width, y+height)
+	bounds := image.Rect(int(math.Round(min[0])), int(math.Round(min[1])), int(math.Round(max[0])), int(math.Round(max[1])))
+	var mask image.Image
+	if s.opacity < 1 {
+		mask = image.NewUniform(color.Alpha{A: uint8(math.Round(255 * s.opacity))})
+	}
+	draw.NearestNeighbor.Scale(r.dst, bounds, src, src.Bounds(), draw.Over, &draw.Options{SrcMask: mask})
+	return nil
+}
+
+// number parses a numeric attribute, ignoring a trailing "px" unit
+func number(attributes map[string]string, name string) (float64, bool) {
+	v, ok := attributes[name]
+	if !ok {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(v), "px"), 64)
+	return f, err == nil
+}
@@ -0,0 +1,306 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package svg
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// namedColors are the CSS color keywords supported by the rasterizer
+var namedColors = map[string]color.NRGBA{
+	"black":   {0x00, 0x00, 0x00, 0xff},
+	"silver":  {0xc0, 0xc0, 0xc0, 0xff},
+	"gray":    {0x80, 0x80, 0x80, 0xff},
+	"grey":    {0x80, 0x80, 0x80, 0xff},
+	"white":   {0xff, 0xff, 0xff, 0xff},
+	"maroon":  {0x80, 0x00, 0x00, 0xff},
+	"red":     {0xff, 0x00, 0x00, 0xff},
+	"purple":  {0x80, 0x00, 0x80, 0xff},
+	"fuchsia": {0xff, 0x00, 0xff, 0xff},
+	"magenta": {0xff, 0x00, 0xff, 0xff},
+	"green":   {0x00, 0x80, 0x00, 0xff},
+	"lime":    {0x00, 0xff, 0x00, 0xff},
+	"olive":   {0x80, 0x80, 0x00, 0xff},
+	"yellow":  {0xff, 0xff, 0x00, 0xff},
+	"navy":    {0x00, 0x00, 0x80, 0xff},
+	"blue":    {0x00, 0x00, 0xff, 0xff},
+	"teal":    {0x00, 0x80, 0x80, 0xff},
+	"aqua":    {0x00, 0xff, 0xff, 0xff},
+	"cyan":    {0x00, 0xff, 0xff, 0xff},
+	"orange":  {0xff, 0xa5, 0x00, 0xff},
+}
+
+// parseColor parses CSS colors, i.e., keywords, hex colors with an optional alpha channel,
+// and rgb()/rgba() functions
+func parseColor(value string) (color.NRGBA, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if c, ok := namedColors[value]; ok {
+		return c, true
+	}
+	if value == "transparent" {
+		return color.NRGBA{}, true
+	}
+	if strings.HasPrefix(value, "#") {
+		return parseHex(value[1:])
+	}
+	if strings.HasPrefix(value, "rgb") && strings.HasSuffix(value, ")") {
+		i := strings.Index(value, "(")
+		if i < 0 {
+			return color.NRGBA{}, false
+		}
+		args := strings.FieldsFunc(value[i+1:len(value)-1], func(r rune) bool {
+			return r == ',' || r == ' ' || r == '/'
+		})
+		if len(args) != 3 && len(args) != 4 {
+			return color.NRGBA{}, false
+		}
+		var channels [4]float64
+		channels[3] = 1
+		for j, arg := range args {
+			v, err := strconv.ParseFloat(strings.TrimSuffix(arg, "%"), 64)
+			if err != nil {
+				return color.NRGBA{}, false
+			}
+			switch {
+			case strings.HasSuffix(arg, "%") && j < 3:
+				v = v * 255 / 100
+			case strings.HasSuffix(arg, "%"):
+				v = v / 100
+			}
+			channels[j] = v
+		}
+		return color.NRGBA{
+			R: channel(channels[0]),
+			G: channel(channels[1]),
+			B: channel(channels[2]),
+			A: channel(channels[3] * 255),
+		}, true
+	}
+	return color.NRGBA{}, false
+}
+
+// parseHex parses hex colors with 3, 4, 6, or 8 digits
+func parseHex(hex string) (color.NRGBA, bool) {
+	if len(hex) == 3 || len(hex) == 4 {
+		long := make([]byte, 0, 2*len(hex))
+		for i := 0; i < len(hex); i++ {
+			long = append(long, hex[i], hex[i])
+		}
+		hex = string(long)
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return color.NRGBA{}, false
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, false
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, true
+}
+
+// channel clamps and rounds a color channel to [0,255]
+func channel(v float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(255, v))))
+}
+
+// withOpacity scales a color's alpha channel by an opacity in [0,1]
+func withOpacity(c color.NRGBA, opacity float64) color.NRGBA {
+	c.A = channel(float64(c.A) * opacity)
+	return c
+}
+
+// gradientStop is a color at an offset along a gradient
+type gradientStop struct {
+	offset float64
+	color  color.NRGBA
+}
+
+// gradient is a linear or radial gradient defined in the SVG document
+type gradient struct {
+	radial bool
+	// userSpace is true for gradients with gradientUnits="userSpaceOnUse", whose coordinates
+	// are given in user units instead of fractions of the shape's bounding box
+	userSpace bool
+	// x1, y1, x2, y2 are the endpoints of linear gradients, cx, cy, r the center and radius of
+	// radial gradients
+	x1, y1, x2, y2 float64
+	cx, cy, r      float64
+	stops          []gradientStop
+}
+
+// newGradient creates a gradient from the attributes of a linearGradient or radialGradient
+// element, using the SVG defaults for missing attributes
+func newGradient(name string, attributes map[string]string) *gradient {
+	g := &gradient{
+		radial:    name == "radialGradient",
+		userSpace: attributes["gradientUnits"] == "userSpaceOnUse",
+	}
+	coordinate := func(name string, fallback float64) float64 {
+		v, ok := attributes[name]
+		if !ok {
+			return fallback
+		}
+		return length(v, fallback)
+	}
+	g.x1, g.y1 = coordinate("x1", 0), coordinate("y1", 0)
+	g.x2, g.y2 = coordinate("x2", 1), coordinate("y2", 0)
+	g.cx, g.cy, g.r = coordinate("cx", 0.5), coordinate("cy", 0.5), coordinate("r", 0.5)
+	return g
+}
+
+// length parses a number or a percentage as a fraction
+func length(v string, fallback float64) float64 {
+	v = strings.TrimSpace(v)
+	if strings.HasSuffix(v, "%") {
+		f, err := strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64)
+		if err != nil {
+			return fallback
+		}
+		return f / 100
+	}
+	f, err := strconv.ParseFloat(strings.TrimSuffix(v, "px"), 64)
+	if err != nil {
+		return fallback
+	}
+	return f
+}
+
+// addStop adds a stop element's color to the gradient
+func (g *gradient) addStop(attributes map[string]string) {
+	c, ok := parseColor(attributes["stop-color"])
+	if !ok {
+		c = namedColors["black"]
+	}
+	if v, ok := number(attributes, "stop-opacity"); ok {
+		c = withOpacity(c, v)
+	}
+	offset := math.Max(0, math.Min(1, length(attributes["offset"], 0)))
+	// offsets never decrease
+	if len(g.stops) > 0 {
+		offset = math.Max(offset, g.stops[len(g.stops)-1].offset)
+	}
+	g.stops = append(g.stops, gradientStop{offset: offset, color: c})
+}
+
+// at returns the gradient's color at position t, padding beyond the first and last stop
+func (g *gradient) at(t float64) color.NRGBA {
+	if len(g.stops) == 0 {
+		return color.NRGBA{}
+	}
+	i := sort.Search(len(g.stops), func(i int) bool { return g.stops[i].offset >= t })
+	if i == 0 {
+		return g.stops[0].color
+	}
+	if i == len(g.stops) {
+		return g.stops[len(g.stops)-1].color
+	}
+	a, b := g.stops[i-1], g.stops[i]
+	if b.offset == a.offset {
+		return b.color
+	}
+	f := (t - a.offset) / (b.offset - a.offset)
+	mix := func(x uint8, y uint8) uint8 {
+		return channel(float64(x) + f*(float64(y)-float64(x)))
+	}
+	return color.NRGBA{
+		R: mix(a.color.R, b.color.R),
+		G: mix(a.color.G, b.color.G),
+		B: mix(a.color.B, b.color.B),
+		A: mix(a.color.A, b.color.A),
+	}
+}
+
+// gradientImage is an image source painting a gradient in pixel space
+type gradientImage struct {
+	gradient *gradient
+	opacity  float64
+	bounds   image.Rectangle
+	// toUser maps pixels to the gradient's coordinate system
+	toUser func(x float64, y float64) (float64, float64)
+}
+
+var _ image.Image = &gradientImage{}
+
+func (g *gradientImage) ColorModel() color.Model {
+	return color.NRGBAModel
+}
+
+func (g *gradientImage) Bounds() image.Rectangle {
+	return g.bounds
+}
+
+func (g *gradientImage) At(x int, y int) color.Color {
+	// sample at the center of the pixel
+	u, v := g.toUser(float64(x)+0.5, float64(y)+0.5)
+	var t float64
+	if g.gradient.radial {
+		if g.gradient.r <= 0 {
+			return withOpacity(g.gradient.at(1), g.opacity)
+		}
+		t = math.Hypot(u-g.gradient.cx, v-g.gradient.cy) / g.gradient.r
+	} else {
+		dx, dy := g.gradient.x2-g.gradient.x1, g.gradient.y2-g.gradient.y1
+		d := dx*dx + dy*dy
+		if d == 0 {
+			return withOpacity(g.gradient.at(1), g.opacity)
+		}
+		t = ((u-g.gradient.x1)*dx + (v-g.gradient.y1)*dy) / d
+	}
+	return withOpacity(g.gradient.at(t), g.opacity)
+}
+
+// paint returns the image source of a fill or stroke value, which is either a color or a
+// reference to a gradient, or nil if nothing is painted
+func (r *rasterizer) paint(value string, opacity float64, subpaths []subpath) image.Image {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "none" || opacity <= 0 {
+		return nil
+	}
+	if strings.HasPrefix(value, "url(#") {
+		id := strings.TrimSuffix(strings.TrimPrefix(value, "url(#"), ")")
+		g, ok := r.gradients[id]
+		if !ok || len(g.stops) == 0 {
+			return nil
+		}
+		src := &gradientImage{gradient: g, opacity: opacity, bounds: r.dst.Bounds()}
+		if g.userSpace {
+			src.toUser = func(x float64, y float64) (float64, float64) {
+				return (x - r.offsetX) / r.scale, (y - r.offsetY) / r.scale
+			}
+		} else {
+			min, max := bounds(subpaths)
+			w, h := math.Max(max[0]-min[0], 1e-9), math.Max(max[1]-min[1], 1e-9)
+			src.toUser = func(x float64, y float64) (float64, float64) {
+				return (x - min[0]) / w, (y - min[1]) / h
+			}
+		}
+		return src
+	}
+	c, ok := parseColor(value)
+	if !ok {
+		return nil
+	}
+	return image.NewUniform(withOpacity(c, opacity))
+}
@@ -0,0 +1,319 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package svg
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/vector"
+)
+
+// subpath is a flattened subpath in pixel space
+type subpath struct {
+	points [][2]float64
+	closed bool
+}
+
+// bounds returns the bounding box of subpaths
+func bounds(subpaths []subpath) (min [2]float64, max [2]float64) {
+	min = [2]float64{math.Inf(1), math.Inf(1)}
+	max = [2]float64{math.Inf(-1), math.Inf(-1)}
+	for _, p := range subpaths {
+		for _, point := range p.points {
+			min[0], min[1] = math.Min(min[0], point[0]), math.Min(min[1], point[1])
+			max[0], max[1] = math.Max(max[0], point[0]), math.Max(max[1], point[1])
+		}
+	}
+	return min, max
+}
+
+// flattener approximates curves in pixel space by line segments
+type flattener struct {
+	r        *rasterizer
+	subpaths []subpath
+	current  *subpath
+	// start is the first point of the current subpath, where drawing continues after closing
+	start [2]float64
+}
+
+func (f *flattener) moveTo(x float64, y float64) {
+	f.start = [2]float64{x, y}
+	f.subpaths = append(f.subpaths, subpath{points: [][2]float64{f.r.point(x, y)}})
+	f.current = &f.subpaths[len(f.subpaths)-1]
+}
+
+func (f *flattener) lineTo(x float64, y float64) {
+	if f.current == nil {
+		f.moveTo(f.start[0], f.start[1])
+	}
+	f.current.points = append(f.current.points, f.r.point(x, y))
+}
+
+// cubicTo flattens a cubic bezier curve from the current point, such that segments are about
+// two pixels long
+func (f *flattener) cubicTo(x1 float64, y1 float64, x2 float64, y2 float64, x float64, y float64) {
+	if f.current == nil {
+		f.moveTo(f.start[0], f.start[1])
+	}
+	p0 := f.current.points[len(f.current.points)-1]
+	p1, p2, p3 := f.r.point(x1, y1), f.r.point(x2, y2), f.r.point(x, y)
+	l := math.Hypot(p1[0]-p0[0], p1[1]-p0[1]) + math.Hypot(p2[0]-p1[0], p2[1]-p1[1]) + math.Hypot(p3[0]-p2[0], p3[1]-p2[1])
+	n := int(math.Max(1, math.Min(128, math.Ceil(l/2))))
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		u := 1 - t
+		a, b, c, d := u*u*u, 3*u*u*t, 3*u*t*t, t*t*t
+		f.current.points = append(f.current.points, [2]float64{
+			a*p0[0] + b*p1[0] + c*p2[0] + d*p3[0],
+			a*p0[1] + b*p1[1] + c*p2[1] + d*p3[1],
+		})
+	}
+}
+
+func (f *flattener) close() {
+	if f.current != nil {
+		f.current.closed = true
+	}
+	f.current = nil
+}
+
+// parsePath flattens path data. Elliptical arcs are approximated by a line to their endpoint.
+func parsePath(d string, r *rasterizer) []subpath {
+	f := &flattener{r: r}
+	tokens := pathPattern.FindAllString(d, -1)
+	var x, y, startX, startY float64
+	// cx, cy is the last control point for smooth curves, reflected for S and T commands
+	var cx, cy float64
+	command, previous := "", ""
+	args := []float64{}
+	arity := map[string]int{"M": 2, "L": 2, "H": 1, "V": 1, "C": 6, "S": 4, "Q": 4, "T": 2, "A": 7, "Z": 0}
+	execute := func() {
+		upper := strings.ToUpper(command)
+		relative := command != upper && upper != "Z"
+		dx, dy := 0.0, 0.0
+		if relative {
+			dx, dy = x, y
+		}
+		switch upper {
+		case "M":
+			x, y = args[0]+dx, args[1]+dy
+			startX, startY = x, y
+			f.moveTo(x, y)
+			// subsequent coordinate pairs are implicit line commands
+			if relative {
+				command = "l"
+			} else {
+				command = "L"
+			}
+		case "L":
+			x, y = args[0]+dx, args[1]+dy
+			f.lineTo(x, y)
+		case "H":
+			x = args[0] + dx
+			f.lineTo(x, y)
+		case "V":
+			y = args[0] + dy
+			f.lineTo(x, y)
+		case "C":
+			f.cubicTo(args[0]+dx, args[1]+dy, args[2]+dx, args[3]+dy, args[4]+dx, args[5]+dy)
+			cx, cy = args[2]+dx, args[3]+dy
+			x, y = args[4]+dx, args[5]+dy
+		case "S":
+			x1, y1 := x, y
+			if p := strings.ToUpper(previous); p == "C" || p == "S" {
+				x1, y1 = 2*x-cx, 2*y-cy
+			}
+			f.cubicTo(x1, y1, args[0]+dx, args[1]+dy, args[2]+dx, args[3]+dy)
+			cx, cy = args[0]+dx, args[1]+dy
+			x, y = args[2]+dx, args[3]+dy
+		case "Q", "T":
+			qx, qy := x, y
+			if upper == "Q" {
+				qx, qy = args[0]+dx, args[1]+dy
+				args = args[2:]
+			} else if p := strings.ToUpper(previous); p == "Q" || p == "T" {
+				qx, qy = 2*x-cx, 2*y-cy
+			}
+			ex, ey := args[0]+dx, args[1]+dy
+			// elevate the quadratic curve to a cubic one
+			f.cubicTo(x+2*(qx-x)/3, y+2*(qy-y)/3, ex+2*(qx-ex)/3, ey+2*(qy-ey)/3, ex, ey)
+			cx, cy = qx, qy
+			x, y = ex, ey
+		case "A":
+			x, y = args[5]+dx, args[6]+dy
+			f.lineTo(x, y)
+		case "Z":
+			f.close()
+			x, y = startX, startY
+		}
+		previous = upper
+	}
+	for _, token := range tokens {
+		v, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			command = token
+			args = args[:0]
+			if _, ok := arity[strings.ToUpper(command)]; !ok {
+				return nil
+			}
+			if strings.ToUpper(command) == "Z" {
+				execute()
+			}
+			continue
+		}
+		if command == "" {
+			return nil
+		}
+		args = append(args, v)
+		if len(args) == arity[strings.ToUpper(command)] {
+			execute()
+			args = args[:0]
+		}
+	}
+	return f.subpaths
+}
+
+// rectangle flattens a rect element, including rounded corners
+func rectangle(attributes map[string]string, r *rasterizer) []subpath {
+	x, _ := number(attributes, "x")
+	y, _ := number(attributes, "y")
+	width, _ := number(attributes, "width")
+	height, _ := number(attributes, "height")
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+	rx, hasRx := number(attributes, "rx")
+	ry, hasRy := number(attributes, "ry")
+	// a missing radius defaults to the other one, as in SVG
+	if !hasRx {
+		rx = ry
+	}
+	if !hasRy {
+		ry = rx
+	}
+	rx, ry = math.Max(0, math.Min(rx, width/2)), math.Max(0, math.Min(ry, height/2))
+	f := &flattener{r: r}
+	if rx == 0 || ry == 0 {
+		f.moveTo(x, y)
+		f.lineTo(x+width, y)
+		f.lineTo(x+width, y+height)
+		f.lineTo(x, y+height)
+		f.close()
+		return f.subpaths
+	}
+	// k is the distance of the control points approximating a quarter ellipse
+	const k = 0.5522847498
+	f.moveTo(x+rx, y)
+	f.lineTo(x+width-rx, y)
+	f.cubicTo(x+width-rx+k*rx, y, x+width, y+ry-k*ry, x+width, y+ry)
+	f.lineTo(x+width, y+height-ry)
+	f.cubicTo(x+width, y+height-ry+k*ry, x+width-rx+k*rx, y+height, x+width-rx, y+height)
+	f.lineTo(x+rx, y+height)
+	f.cubicTo(x+rx-k*rx, y+height, x, y+height-ry+k*ry, x, y+height-ry)
+	f.lineTo(x, y+ry)
+	f.cubicTo(x, y+ry-k*ry, x+rx-k*rx, y, x+rx, y)
+	f.close()
+	return f.subpaths
+}
+
+// circle flattens a circle element
+func circle(attributes map[string]string, r *rasterizer) []subpath {
+	cx, _ := number(attributes, "cx")
+	cy, _ := number(attributes, "cy")
+	radius, _ := number(attributes, "r")
+	if radius <= 0 {
+		return nil
+	}
+	n := int(math.Max(16, math.Min(256, math.Ceil(2*math.Pi*radius*r.scale/2))))
+	points := make([][2]float64, 0, n)
+	for i := 0; i < n; i++ {
+		phi := 2 * math.Pi * float64(i) / float64(n)
+		points = append(points, r.point(cx+radius*math.Cos(phi), cy+radius*math.Sin(phi)))
+	}
+	return []subpath{{points: points, closed: true}}
+}
+
+// polygon adds a closed polygon to the vector rasterizer
+func polygon(z *vector.Rasterizer, points [][2]float64) {
+	if len(points) < 2 {
+		return
+	}
+	z.MoveTo(float32(points[0][0]), float32(points[0][1]))
+	for _, p := range points[1:] {
+		z.LineTo(float32(p[0]), float32(p[1]))
+	}
+	z.ClosePath()
+}
+
+// orientedPolygon adds a polygon to the vector rasterizer in a consistent orientation, such
+// that overlapping polygons add up instead of cancelling each other out
+func orientedPolygon(z *vector.Rasterizer, points [][2]float64) {
+	area := 0.0
+	for i := range points {
+		p, q := points[i], points[(i+1)%len(points)]
+		area += p[0]*q[1] - q[0]*p[1]
+	}
+	if area < 0 {
+		reversed := make([][2]float64, len(points))
+		for i, p := range points {
+			reversed[len(points)-1-i] = p
+		}
+		points = reversed
+	}
+	polygon(z, points)
+}
+
+// stroke adds the outline of a subpath to the vector rasterizer as a quad for each segment
+// and round joins between them. Ends of open subpaths are butt-capped.
+func stroke(z *vector.Rasterizer, p subpath, width float64) {
+	points := p.points
+	if p.closed && len(points) > 1 {
+		points = append(append([][2]float64{}, points...), points[0])
+	}
+	half := width / 2
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		dx, dy := b[0]-a[0], b[1]-a[1]
+		l := math.Hypot(dx, dy)
+		if l == 0 {
+			continue
+		}
+		nx, ny := -dy/l*half, dx/l*half
+		orientedPolygon(z, [][2]float64{
+			{a[0] + nx, a[1] + ny},
+			{b[0] + nx, b[1] + ny},
+			{b[0] - nx, b[1] - ny},
+			{a[0] - nx, a[1] - ny},
+		})
+	}
+	// joins are drawn at interior vertices, and at all vertices of closed subpaths
+	first, last := 1, len(points)-1
+	if p.closed {
+		first, last = 0, len(points)-1
+	}
+	n := int(math.Max(8, math.Min(64, math.Ceil(math.Pi*width/2))))
+	for i := first; i < last; i++ {
+		join := make([][2]float64, n)
+		for j := range join {
+			phi := 2 * math.Pi * float64(j) / float64(n)
+			join[j] = [2]float64{points[i][0] + half*math.Cos(phi), points[i][1] + half*math.Sin(phi)}
+		}
+		orientedPolygon(z, join)
+	}
+}
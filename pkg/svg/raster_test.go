@@ -0,0 +1,76 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package svg
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestRasterize(t *testing.T) {
+	document, err := Template([]string{
+		`<rect x="0" y="0" width="5" height="10" fill="#ff0000" />`,
+		`<path d="M 5 0 L 10 0 L 10 10 L 5 10 Z" fill="rgba(0, 0, 255, 0.5)" />`,
+	}, true, "0 0 10 10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := Rasterize(document, &RasterOptions{DPI: 2 * DefaultDPI})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 20 || b.Dy() != 20 {
+		t.Fatalf("expected 20x20 image, found %dx%d", b.Dx(), b.Dy())
+	}
+	if c := img.NRGBAAt(4, 10); c != (color.NRGBA{R: 0xff, A: 0xff}) {
+		t.Errorf("expected red rect, found %v", c)
+	}
+	if c := img.NRGBAAt(15, 10); c.B != 0xff || c.A < 0x7f || c.A > 0x80 {
+		t.Errorf("expected translucent blue path, found %v", c)
+	}
+}
+
+func TestPNG(t *testing.T) {
+	out, err := PNG([]string{`<circle cx="50" cy="25" r="10" />`}, true, "0 0 100 50", &RasterOptions{Width: 40})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 40 || b.Dy() != 20 {
+		t.Fatalf("expected 40x20 image, found %dx%d", b.Dx(), b.Dy())
+	}
+	if _, _, _, a := img.At(20, 10).RGBA(); a != 0xffff {
+		t.Errorf("expected opaque circle at the center, found alpha %d", a)
+	}
+	if _, _, _, a := img.At(0, 0).RGBA(); a != 0 {
+		t.Errorf("expected transparent background, found alpha %d", a)
+	}
+
+	if _, err := Rasterize(strings.NewReader(`<svg></svg>`), nil); !errors.Is(err, ErrViewBox) {
+		t.Errorf("expected ErrViewBox, found %v", err)
+	}
+	if _, err := Rasterize(bytes.NewBufferString(`<svg viewBox="0 0 1 1"><rect`), nil); err == nil {
+		t.Error("expected malformed document to fail")
+	}
+}
@@ -18,6 +18,7 @@ package svg
 
 import (
 	"bytes"
+	"strings"
 	"text/template"
 
 	"github.com/lithammer/dedent"
@@ -36,22 +37,58 @@ var DefaultSvgTemplate = dedent.Dedent(`
     xmlns:ev="http://www.w3.org/2001/xml-events"
     xmlns:xlink="http://www.w3.org/1999/xlink"
   >
+    {{ if .Defs -}}
+    <defs>
+    {{ range $_, $def := .Defs -}}
+    {{ $def }}
+    {{ end -}}
+    </defs>
+    {{ end -}}
     {{ range $_, $el := .Elements -}}
     {{ $el }}
     {{ end -}}
   </svg>
 `)
 
+// camelCaseElements contains the SVG elements whose names are not lowercase. The formatter
+// lowercases closing and self-closing tags, which breaks case-sensitive SVG parsers, so their
+// case is restored.
+var camelCaseElements = []string{
+	"linearGradient",
+	"radialGradient",
+	"clipPath",
+	"animateTransform",
+	"animateMotion",
+	"feFlood",
+	"feComposite",
+}
+
+// restoreCase restores the case of tags of camel case SVG elements
+var restoreCase = func() *strings.Replacer {
+	var pairs []string
+	for _, element := range camelCaseElements {
+		lower := strings.ToLower(element)
+		pairs = append(pairs,
+			"<"+lower+" ", "<"+element+" ",
+			"<"+lower+">", "<"+element+">",
+			"</"+lower+">", "</"+element+">",
+		)
+	}
+	return strings.NewReplacer(pairs...)
+}()
+
 type TemplateBindings struct {
 	PreserveAspectRatio string
 	Elements            []string
 	Viewbox             string
+	Defs                []string
 }
 
 // Template executes the default SVG template and writes all previously created SVG elements to the body
+// Definitions referenced by the elements, e.g., gradients, are written to the SVG's <defs>, if any
 // Returns the template as string
 // Returns an error if any failures occur.
-func Template(elements []string, preserveAspectRatio bool, viewBox string) (*bytes.Buffer, error) {
+func Template(elements []string, preserveAspectRatio bool, viewBox string, defs ...string) (*bytes.Buffer, error) {
 	tmpl, err := template.New("svg").Parse(DefaultSvgTemplate)
 	if err != nil {
 		return nil, err
@@ -66,6 +103,7 @@ func Template(elements []string, preserveAspectRatio bool, viewBox string) (*byt
 		PreserveAspectRatio: preservanceKey,
 		Elements:            elements,
 		Viewbox:             viewBox,
+		Defs:                defs,
 	}
 	rawBuffer := &bytes.Buffer{}
 	err = tmpl.Execute(rawBuffer, bindings)
@@ -73,7 +111,7 @@ func Template(elements []string, preserveAspectRatio bool, viewBox string) (*byt
 		return nil, err
 	}
 
-	outBuf := bytes.NewBuffer(gohtml.FormatBytes(rawBuffer.Bytes()))
+	outBuf := bytes.NewBufferString(restoreCase.Replace(gohtml.Format(rawBuffer.String())))
 
 	return outBuf, nil
 }
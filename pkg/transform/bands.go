@@ -0,0 +1,140 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transform
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+const (
+	// LowBandCutoff is the upper frequency of the low band in Hz
+	LowBandCutoff float64 = 250
+	// HighBandCutoff is the lower frequency of the high band in Hz
+	HighBandCutoff float64 = 4000
+
+	// bandFrameSize is the number of samples transformed at once, i.e., the length of the FFT
+	bandFrameSize int = 1024
+)
+
+// Bands contains the spectral energy of a chunk in the low, mid, and high frequency band, split
+// at LowBandCutoff and HighBandCutoff. Energies are only comparable relative to each other.
+type Bands struct {
+	Low  float64
+	Mid  float64
+	High float64
+}
+
+func (b *Bands) add(o Bands) {
+	b.Low += o.Low
+	b.Mid += o.Mid
+	b.High += o.High
+}
+
+// Total returns the sum of the energies of all bands
+func (b Bands) Total() float64 {
+	return b.Low + b.Mid + b.High
+}
+
+// bandAnalyzer splits a signal into frames of bandFrameSize samples, and accumulates the energy
+// of each frame's spectrum per band
+type bandAnalyzer struct {
+	sampleRate int
+	frame      []float64
+	window     []float64
+	buffer     []complex128
+}
+
+func newBandAnalyzer(sampleRate int) *bandAnalyzer {
+	return &bandAnalyzer{
+		sampleRate: sampleRate,
+		frame:      make([]float64, 0, bandFrameSize),
+		window:     windowCoefficients(&Window{Algorithm: Hann}, bandFrameSize),
+		buffer:     make([]complex128, bandFrameSize),
+	}
+}
+
+// add adds a sample of the signed signal, and returns the energy of the frame when the sample
+// completes it
+func (a *bandAnalyzer) add(sample float64) (Bands, bool) {
+	a.frame = append(a.frame, sample)
+	if len(a.frame) < bandFrameSize {
+		return Bands{}, false
+	}
+	return a.flush(), true
+}
+
+// flush analyzes the pending samples zero-padded to a full frame and resets the frame. Returns
+// empty bands when no samples are pending.
+func (a *bandAnalyzer) flush() Bands {
+	bands := Bands{}
+	if len(a.frame) == 0 {
+		return bands
+	}
+	for i := range a.buffer {
+		a.buffer[i] = 0
+		if i < len(a.frame) {
+			a.buffer[i] = complex(a.frame[i]*a.window[i], 0)
+		}
+	}
+	a.frame = a.frame[:0]
+	fft(a.buffer)
+
+	resolution := float64(a.sampleRate) / float64(bandFrameSize)
+	for k := 1; k <= bandFrameSize/2; k++ {
+		energy := real(a.buffer[k])*real(a.buffer[k]) + imag(a.buffer[k])*imag(a.buffer[k])
+		switch frequency := float64(k) * resolution; {
+		case frequency < LowBandCutoff:
+			bands.Low += energy
+		case frequency < HighBandCutoff:
+			bands.Mid += energy
+		default:
+			bands.High += energy
+		}
+	}
+	return bands
+}
+
+// fft computes the discrete Fourier transform of x in place with the iterative radix-2
+// Cooley-Tukey algorithm. The length of x must be a power of two.
+func fft(x []complex128) {
+	n := len(x)
+	// bit-reversal permutation
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	for length := 2; length <= n; length <<= 1 {
+		w := cmplx.Exp(complex(0, -2*math.Pi/float64(length)))
+		for start := 0; start < n; start += length {
+			wk := complex(1, 0)
+			for k := 0; k < length/2; k++ {
+				even := x[start+k]
+				odd := x[start+k+length/2] * wk
+				x[start+k] = even + odd
+				x[start+k+length/2] = even - odd
+				wk *= w
+			}
+		}
+	}
+}
@@ -34,16 +34,62 @@ func toMono(samples [][2]float64) []float64 {
 	return sc
 }
 
-// normalize implements regular feature scaling to [0,1] on a slice of float64
-func normalize(samples []float64) []float64 {
-	localMax := max(samples)
-	localMin := min(samples)
-	scalar := localMax - localMin
+// toChannels converts a slice of stereo samples to the visual signals of a channel mode, i.e.,
+// a single signal for all modes but ChannelSplitStereo, which yields the left and the right
+// channel. Like toMono, channels are mixed before taking the absolute value.
+func toChannels(samples [][2]float64, mode ChannelMode) [][]float64 {
+	switch mode {
+	case ChannelLeft, ChannelRight, ChannelSplitStereo:
+		left := make([]float64, len(samples))
+		right := make([]float64, len(samples))
+		for i, sample := range samples {
+			left[i] = math.Abs(sample[0])
+			right[i] = math.Abs(sample[1])
+		}
+		switch mode {
+		case ChannelLeft:
+			return [][]float64{left}
+		case ChannelRight:
+			return [][]float64{right}
+		}
+		return [][]float64{left, right}
+	case ChannelSide:
+		sc := make([]float64, len(samples))
+		for i, sample := range samples {
+			sc[i] = math.Abs(sample[0]-sample[1]) / 2
+		}
+		return [][]float64{sc}
+	}
+	// the mid signal equals the mono sum
+	return [][]float64{toMono(samples)}
+}
+
+// toSignedChannels converts a slice of stereo samples to the signed signals of a channel mode,
+// i.e., the signals of toChannels before taking the absolute value
+func toSignedChannels(samples [][2]float64, mode ChannelMode) [][]float64 {
+	left := make([]float64, len(samples))
+	right := make([]float64, len(samples))
+	for i, sample := range samples {
+		left[i] = sample[0]
+		right[i] = sample[1]
+	}
+	switch mode {
+	case ChannelLeft:
+		return [][]float64{left}
+	case ChannelRight:
+		return [][]float64{right}
+	case ChannelSplitStereo:
+		return [][]float64{left, right}
+	}
 	sc := make([]float64, len(samples))
 	for i, sample := range samples {
-		sc[i] = (sample - localMin) / scalar
+		if mode == ChannelSide {
+			sc[i] = (sample[0] - sample[1]) / 2
+		} else {
+			sc[i] = (sample[0] + sample[1]) / 2
+		}
 	}
-	return sc
+	return [][]float64{sc}
 }
 
 // sum takes a slice of samples and sums them up.
@@ -119,6 +165,16 @@ func rootMeanSquare(samples []float64) float64 {
 	return math.Sqrt(meanSquare(samples))
 }
 
+// decibels converts an amplitude to dBFS and maps [floor, 0] linearly to [0,1]. Amplitudes
+// below the floor map to 0, amplitudes above full scale exceed 1.
+func decibels(amplitude, floor float64) float64 {
+	if amplitude <= 0 {
+		return 0
+	}
+	db := 20 * math.Log10(amplitude)
+	return math.Max(0, 1-db/floor)
+}
+
 // clamp clips a value between a lower (min) and an upper bound (max)
 func clamp(value, min, max float64) float64 {
 	return math.Max(min, math.Min(max, value))
@@ -0,0 +1,240 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transform
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// MomentaryWindow is the length of the sliding window of momentary loudness per EBU R 128
+	MomentaryWindow time.Duration = 400 * time.Millisecond
+	// ShortTermWindow is the length of the sliding window of short-term loudness per EBU R 128
+	ShortTermWindow time.Duration = 3 * time.Second
+	// LoudnessFloor is the loudness of silence in LUFS, i.e., the absolute gating threshold of
+	// BS.1770, below which signals do not contribute to the loudness of a programme
+	LoudnessFloor float64 = -70
+)
+
+// biquad is a second order IIR filter in direct form I
+type biquad struct {
+	b0, b1, b2 float64
+	a1, a2     float64
+	x1, x2     float64
+	y1, y2     float64
+}
+
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+// kWeighting is the K-weighting filter of ITU-R BS.1770, i.e., a high shelf modeling the
+// acoustic effects of the head followed by the RLB high-pass filter. The coefficients are
+// derived for arbitrary sample rates from the analog prototypes, which reproduces the
+// coefficients given in BS.1770 for 48 kHz.
+type kWeighting struct {
+	shelf    biquad
+	highpass biquad
+}
+
+func newKWeighting(sampleRate int) *kWeighting {
+	fs := float64(sampleRate)
+	k := &kWeighting{}
+
+	// high shelf
+	f0 := 1681.974450955533
+	gain := 3.999843853973347
+	q := 0.7071752369554196
+	K := math.Tan(math.Pi * f0 / fs)
+	vh := math.Pow(10, gain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + K/q + K*K
+	k.shelf = biquad{
+		b0: (vh + vb*K/q + K*K) / a0,
+		b1: 2 * (K*K - vh) / a0,
+		b2: (vh - vb*K/q + K*K) / a0,
+		a1: 2 * (K*K - 1) / a0,
+		a2: (1 - K/q + K*K) / a0,
+	}
+
+	// RLB high-pass
+	f0 = 38.13547087602444
+	q = 0.5003270373238773
+	K = math.Tan(math.Pi * f0 / fs)
+	a0 = 1 + K/q + K*K
+	k.highpass = biquad{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (K*K - 1) / a0,
+		a2: (1 - K/q + K*K) / a0,
+	}
+	return k
+}
+
+func (k *kWeighting) process(x float64) float64 {
+	return k.highpass.process(k.shelf.process(x))
+}
+
+// loudnessMeter computes the K-weighted energy of the signal of a single series, and tracks the
+// mean energy over the momentary and short-term sliding windows
+type loudnessMeter struct {
+	mode    ChannelMode
+	channel int
+	// filters holds the K-weighting filter state of each signal contributing to the series
+	filters []*kWeighting
+	inputs  []float64
+
+	// ring contains the energy of the last len(ring) samples, i.e., of the short-term window
+	ring []float64
+	pos  int
+	seen int
+	// windows contains the length of the momentary and short-term window in samples, and sums
+	// the total energy within each window
+	windows [2]int
+	sums    [2]float64
+}
+
+func newLoudnessMeter(sampleRate int, mode ChannelMode, channel int) *loudnessMeter {
+	m := &loudnessMeter{
+		mode:    mode,
+		channel: channel,
+		windows: [2]int{
+			int(MomentaryWindow.Seconds() * float64(sampleRate)),
+			int(ShortTermWindow.Seconds() * float64(sampleRate)),
+		},
+	}
+	m.ring = make([]float64, m.windows[1])
+	m.inputs = m.signals([2]float64{}, nil)
+	m.filters = make([]*kWeighting, len(m.inputs))
+	for i := range m.filters {
+		m.filters[i] = newKWeighting(sampleRate)
+	}
+	return m
+}
+
+// signals returns the signals of a stereo sample that contribute to the loudness of the
+// meter's series. The mono sum follows BS.1770 by summing the energy of both channels, all
+// other channel modes weigh the signal of toSignedChannels.
+func (m *loudnessMeter) signals(sample [2]float64, out []float64) []float64 {
+	out = out[:0]
+	switch m.mode {
+	case ChannelLeft:
+		return append(out, sample[0])
+	case ChannelRight:
+		return append(out, sample[1])
+	case ChannelSplitStereo:
+		return append(out, sample[m.channel])
+	case ChannelMid:
+		return append(out, (sample[0]+sample[1])/2)
+	case ChannelSide:
+		return append(out, (sample[0]-sample[1])/2)
+	}
+	return append(out, sample[0], sample[1])
+}
+
+// add filters a stereo sample and returns its K-weighted energy
+func (m *loudnessMeter) add(sample [2]float64) float64 {
+	m.inputs = m.signals(sample, m.inputs)
+	energy := float64(0)
+	for i, x := range m.inputs {
+		y := m.filters[i].process(x)
+		energy += y * y
+	}
+
+	for w, length := range m.windows {
+		m.sums[w] += energy
+		if m.seen >= length {
+			// the oldest sample of the window leaves it
+			m.sums[w] -= m.ring[(m.pos-length+len(m.ring))%len(m.ring)]
+		}
+	}
+	m.ring[m.pos] = energy
+	m.pos = (m.pos + 1) % len(m.ring)
+	m.seen++
+	if m.pos == 0 {
+		m.resum()
+	}
+	return energy
+}
+
+// resum recomputes the window sums from the ring buffer, which discards the rounding errors the
+// running sums accumulate, e.g., to below zero for silence following loud passages
+func (m *loudnessMeter) resum() {
+	for w, length := range m.windows {
+		n := length
+		if m.seen < n {
+			n = m.seen
+		}
+		m.sums[w] = 0
+		for i := 1; i <= n; i++ {
+			m.sums[w] += m.ring[(m.pos-i+len(m.ring))%len(m.ring)]
+		}
+	}
+}
+
+// window returns the mean energy of the momentary (0) or short-term (1) window ending at the
+// last sample, or of all samples seen so far if fewer than the window's length
+func (m *loudnessMeter) window(w int) float64 {
+	n := m.windows[w]
+	if m.seen < n {
+		n = m.seen
+	}
+	if n == 0 {
+		return 0
+	}
+	// rounding errors of the running sums may still be negative in silence
+	return math.Max(0, m.sums[w]/float64(n))
+}
+
+// loudnessWindow returns the index of a loudness aggregator's window for loudnessMeter.window,
+// or -1 for all other aggregators
+func loudnessWindow(mode Aggregator) int {
+	switch mode {
+	case AggregatorLoudnessMomentary:
+		return 0
+	case AggregatorLoudnessShortTerm:
+		return 1
+	}
+	return -1
+}
+
+// loudness returns the block value of a loudness aggregator for a chunk of n samples with the
+// given total energy, whose last sample completes the meter's sliding windows at windowEnergy.
+// Chunks longer than the window use their own mean energy, shorter chunks use the window's.
+// Blocks are the amplitude 10^(LUFS/20) of a full-scale sine of equal loudness, such that
+// ScaleDecibels maps loudness in LUFS.
+func (r *ReaderContext) loudness(window int, energy float64, n int, windowEnergy float64) float64 {
+	mean := windowEnergy
+	if n > 0 && n >= r.meters[0].windows[window] {
+		mean = energy / float64(n)
+	}
+	return math.Pow(10, Loudness(mean)/20)
+}
+
+// Loudness converts the mean K-weighted energy of a signal to LUFS according to BS.1770, with
+// LoudnessFloor for silence
+func Loudness(energy float64) float64 {
+	if energy <= 0 {
+		return LoudnessFloor
+	}
+	return math.Max(LoudnessFloor, -0.691+10*math.Log10(energy))
+}
@@ -17,12 +17,45 @@ limitations under the License.
 package transform
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"strings"
+	"time"
+
+	"github.com/zoomoid/waveman2/pkg/decoder"
+	"github.com/zoomoid/waveman2/pkg/decoder/registry"
+)
+
+// Format determines which decoder of the registry is used to read samples from the source
+type Format string
+
+const (
+	FormatMp3   Format = "mp3"
+	FormatWav   Format = "wav"
+	FormatFlac  Format = "flac"
+	FormatOgg   Format = "ogg"
+	FormatEmpty Format = ""
 )
 
+// Formats returns the names of all formats in the decoder registry, including those registered
+// after initialization
+func Formats() []string {
+	return registry.Names()
+}
+
+// FormatFromExtension returns the format registered for a file extension such as ".wav".
+// Unknown extensions fall back to DefaultFormat.
+func FormatFromExtension(ext string) Format {
+	f, err := registry.FromExtension(ext)
+	if err != nil {
+		return DefaultFormat
+	}
+	return Format(f.Name)
+}
+
 type DownsamplingMode string
 
 const (
@@ -41,11 +74,66 @@ const (
 	AggregatorAverage        Aggregator = "avg"
 	AggregatorRoundedAverage Aggregator = "rounded-avg"
 	AggregatorMax            Aggregator = "max"
+	// AggregatorPeak is an alias of AggregatorMax, as commonly used for peak envelope layers
+	AggregatorPeak Aggregator = "peak"
+	// AggregatorMinMax yields the signed maximum and minimum of each chunk, i.e., the upper
+	// and lower envelope of the signal before taking its absolute value. See
+	// ReaderContext.LowerEnvelope.
+	AggregatorMinMax         Aggregator = "min-max"
 	AggregatorMeanSquare     Aggregator = "mean-square"
 	AggregatorRootMeanSquare Aggregator = "rms"
-	AggregatorEmpty          Aggregator = ""
+	// AggregatorLoudnessMomentary yields the K-weighted momentary loudness of ITU-R BS.1770,
+	// i.e., the loudness over a sliding window of 400ms ending at each chunk's end, or over
+	// the chunk itself if it is longer. Use ScaleDecibels to scale blocks in LUFS.
+	AggregatorLoudnessMomentary Aggregator = "lufs-momentary"
+	// AggregatorLoudnessShortTerm yields the K-weighted short-term loudness of ITU-R BS.1770
+	// over a sliding window of 3s, see AggregatorLoudnessMomentary
+	AggregatorLoudnessShortTerm Aggregator = "lufs-short-term"
+	AggregatorEmpty             Aggregator = ""
+)
+
+// ChannelMode determines which signal is derived from the stereo channels of the source
+type ChannelMode string
+
+const (
+	// ChannelMono sums both channels, such that out-of-phase signals cancel out
+	ChannelMono ChannelMode = "mono"
+	// ChannelLeft only uses the left channel
+	ChannelLeft ChannelMode = "left"
+	// ChannelRight only uses the right channel
+	ChannelRight ChannelMode = "right"
+	// ChannelMid uses the mid signal (L+R)/2, which is equivalent to ChannelMono
+	ChannelMid ChannelMode = "mid"
+	// ChannelSide uses the side signal (L-R)/2, i.e., the stereo content of the source
+	ChannelSide ChannelMode = "side"
+	// ChannelSplitStereo aggregates the left and right channel separately and yields two series
+	// of blocks, see ReaderContext.RightBlocks
+	ChannelSplitStereo ChannelMode = "split-stereo"
+	// ChannelEmpty is used for catching uninitialized channel modes
+	ChannelEmpty ChannelMode = ""
+)
+
+// ChannelModes contains all supported channel modes for Cobra flag autocompletion
+var ChannelModes = []string{"mono", "left", "right", "mid", "side", "split-stereo"}
+
+// Scale determines how the amplitude of blocks is mapped before normalization and windowing
+type Scale string
+
+const (
+	// ScaleLinear retains the amplitude of blocks
+	ScaleLinear Scale = "linear"
+	// ScaleDecibels maps amplitudes in dBFS linearly from [ReaderOptions.DBFloor, 0] to [0,1],
+	// which lifts quiet passages
+	ScaleDecibels Scale = "db"
+	// ScalePower maps amplitudes x to x^ReaderOptions.Gamma
+	ScalePower Scale = "power"
+	// ScaleEmpty is used for catching uninitialized scales
+	ScaleEmpty Scale = ""
 )
 
+// Scales contains all supported scales for Cobra flag autocompletion
+var Scales = []string{"linear", "db", "power"}
+
 type WindowAlgorithm int
 
 var WindowAlgorithms = []string{Rectangular.String(), Hann.String(), Tukey.String(), PlanckTaper.String()}
@@ -88,12 +176,18 @@ func WindowAlgorithmFromString(a string) WindowAlgorithm {
 	}
 }
 
-var Aggregators = []string{"rms", "mean-square", "rounded-avg", "avg", "max"}
+var Aggregators = []string{"rms", "mean-square", "rounded-avg", "avg", "max", "peak", "min-max", "lufs-momentary", "lufs-short-term"}
 
 var (
-	ErrNoFile error = errors.New("no file given")
+	ErrNoFile            error = errors.New("no file given")
+	ErrUnknownSampleRate error = errors.New("sample rate of the audio source is unknown")
 
+	DefaultFormat            Format           = FormatMp3
 	DefaultAggregator        Aggregator       = AggregatorRootMeanSquare
+	DefaultChannelMode       ChannelMode      = ChannelMono
+	DefaultScale             Scale            = ScaleLinear
+	DefaultDBFloor           float64          = -60
+	DefaultGamma             float64          = 0.5
 	DefaultRoundingPrecision uint             = 3
 	DefaultDownsamplingMode  DownsamplingMode = DownsamplingCenter
 	DefaultPrecision         Precision        = PrecisionFull
@@ -110,15 +204,70 @@ var (
 	}
 )
 
+// Levels describes the range of the blocks of a source after scaling and before normalization,
+// which Normalize maps to the full height
+type Levels struct {
+	// Min and Max are the minimum and maximum block of all unsigned series
+	Min float64
+	Max float64
+	// Peak is the maximum absolute block of the signed envelopes of AggregatorMinMax
+	Peak float64
+}
+
+// Merge returns the levels spanning both l and o, e.g., for normalizing a batch of sources
+// jointly
+func (l Levels) Merge(o Levels) Levels {
+	return Levels{
+		Min:  math.Min(l.Min, o.Min),
+		Max:  math.Max(l.Max, o.Max),
+		Peak: math.Max(l.Peak, o.Peak),
+	}
+}
+
 type ReaderOptions struct {
-	Chunks       int
-	Aggregator   Aggregator
+	Format     Format
+	Chunks     int
+	Aggregator Aggregator
+	// Layers lists additional aggregators computed in the same pass as Aggregator, e.g., a
+	// peak envelope drawn behind RMS blocks. See ReaderContext.Layers.
+	Layers       []Aggregator
+	Channels     ChannelMode
 	Precision    Precision
 	Downsampling DownsamplingMode
 
 	Normalize bool
+	// Reference are the levels Normalize maps to the full height instead of the source's own
+	// when not nil, e.g., the merged Levels of a batch of sources, such that the sources retain
+	// their relative levels. Otherwise, Normalize scales each source individually.
+	Reference *Levels
 	Window    *Window
 	Clamping  *Clamping
+
+	// Streaming aggregates the source in a single pass without knowing its length up front,
+	// which allows for transforming non-seekable sources without buffering them in memory.
+	// Streaming is used regardless when the decoder cannot determine the source's length.
+	Streaming bool
+
+	// ChunkDuration derives the number of chunks from the duration of the source, such that
+	// each chunk spans approximately ChunkDuration of audio. Takes precedence over Chunks
+	// when positive. In streaming mode, each chunk spans exactly ChunkDuration, except for
+	// the last one, which covers the remainder of the source.
+	ChunkDuration time.Duration
+
+	// Bands analyzes the spectrum of each chunk and yields the energy of its low, mid, and high
+	// frequency band, see ReaderContext.Bands. Requires the decoder to report its sample rate.
+	Bands bool
+
+	// Spectrogram computes the spectrogram of the first series of the channel mode when
+	// non-nil, see ReaderContext.Spectrogram. Requires the decoder to report its sample rate.
+	Spectrogram *SpectrogramOptions
+
+	// Scale maps the amplitude of blocks before normalization and windowing. DBFloor is the
+	// lowest level in dBFS for ScaleDecibels and must be negative, Gamma is the exponent for
+	// ScalePower and must be positive.
+	Scale   Scale
+	DBFloor float64
+	Gamma   float64
 }
 
 type Window struct {
@@ -165,11 +314,36 @@ const (
 type Transformer ReaderContext
 
 type ReaderContext struct {
-	chunks             int
-	mode               Aggregator
-	reader             io.Reader
-	decoder            *Mp3Decoder
-	blocks             []float64
+	chunks  int
+	mode    Aggregator
+	reader  io.Reader
+	decoder decoder.Decoder
+	// aggregators lists the aggregators computed in a single pass, starting with mode,
+	// followed by any additional layers
+	aggregators []Aggregator
+	// layers contains the blocks of each aggregator for each channel of the channel mode,
+	// i.e., two series per aggregator for ChannelSplitStereo and one otherwise
+	layers [][][]float64
+	// lower contains the lower envelopes of each channel for AggregatorMinMax, in which case
+	// layers contains the upper envelopes, and nil for all other aggregators
+	lower [][][]float64
+	// signed is set when any aggregator requires the signed signal
+	signed bool
+	// meters contains the loudness meter of each channel when any aggregator is a loudness
+	// aggregator, and is nil otherwise
+	meters []*loudnessMeter
+	// analyzer splits the signal of the first series into frequency bands when
+	// ReaderOptions.Bands is set, and is nil otherwise
+	analyzer *bandAnalyzer
+	bands    []Bands
+	// stft computes the spectrogram when ReaderOptions.Spectrogram is set, and is nil
+	// otherwise
+	stft               *stft
+	spectrogram        *Spectrogram
+	scale              Scale
+	dbFloor            float64
+	gamma              float64
+	channelMode        ChannelMode
 	chunkSize          int
 	precision          Precision
 	samplesPerChunk    int
@@ -179,9 +353,99 @@ type ReaderContext struct {
 	windowParam        float64
 	windowAlgo         WindowAlgorithm
 	normalize          bool
+	reference          *Levels
+	streaming          bool
+	// levels is the range of all series after scaling, before normalization
+	levels Levels
+	// blockSamples is the number of samples aggregated per block when streaming with a fixed
+	// chunk duration, and 0 otherwise
+	blockSamples int
+	// samples is the total number of samples of the source, which is only known after
+	// processing in streaming mode
+	samples int64
 }
 
+// Metadata describes the audio source of a transformer
+type Metadata struct {
+	// SampleRate is the sample rate of the source in Hz, or 0 if the decoder does not
+	// report it
+	SampleRate int
+	// Channels is the number of channels of the source before folding down to stereo, or 0
+	// if the decoder does not report it
+	Channels int
+	// Samples is the total number of samples per channel of the source
+	Samples int64
+	// Duration is the length of the source, or 0 if the sample rate is unknown
+	Duration time.Duration
+	// Chunks is the number of blocks
+	Chunks int
+	// SamplesPerChunk is the number of source samples each chunk spans before downsampling.
+	// In streaming mode without a fixed chunk duration, chunks vary slightly in length, and
+	// SamplesPerChunk is their average.
+	SamplesPerChunk int
+}
+
+// New constructs a transformer reading from reader with the decoder registered for
+// options.Format and processes the entire audio source. When options.Format is empty, the
+// format is detected from the source's magic bytes, falling back to DefaultFormat.
 func New(options *ReaderOptions, reader io.Reader) (*ReaderContext, error) {
+	// chunking requires the length of the source, which decoders can only determine by
+	// seeking, so non-seekable sources such as pipes are buffered in memory entirely,
+	// unless the source is aggregated in streaming mode
+	if !options.Streaming && !isSeekable(reader) {
+		b, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(b)
+	}
+	// the detected format is kept local, as callers may reuse options for sources of
+	// different formats
+	name := options.Format
+	if name == FormatEmpty {
+		sniffed, r, err := registry.Sniff(reader)
+		reader = r
+		switch {
+		case err == nil:
+			name = Format(sniffed.Name)
+		case errors.Is(err, registry.ErrUnknownFormat):
+			name = DefaultFormat
+		default:
+			return nil, err
+		}
+	}
+	format, err := registry.Lookup(string(name))
+	if err != nil {
+		return nil, err
+	}
+	d, err := format.New(reader)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err := NewFromDecoder(options, d)
+	if err != nil {
+		return nil, err
+	}
+	ctx.reader = reader
+	return ctx, nil
+}
+
+// isSeekable reports whether r is io.Seeker and seeking actually succeeds, which is not the
+// case for e.g. an *os.File reading from a pipe
+func isSeekable(r io.Reader) bool {
+	s, ok := r.(io.Seeker)
+	if !ok {
+		return false
+	}
+	_, err := s.Seek(0, io.SeekCurrent)
+	return err == nil
+}
+
+// NewFromDecoder constructs a transformer reading samples from an existing decoder, e.g., one
+// provided by a library user for audio that is already decoded in memory, and processes the
+// entire audio source. options.Format is ignored. When the decoder cannot report the length of
+// the source, the source is aggregated in streaming mode.
+func NewFromDecoder(options *ReaderOptions, d decoder.Decoder) (*ReaderContext, error) {
 	if options.Chunks == 0 {
 		options.Chunks = DefaultChunks
 	}
@@ -200,23 +464,149 @@ func New(options *ReaderOptions, reader io.Reader) (*ReaderContext, error) {
 	if options.Downsampling == DownsamplingEmpty {
 		options.Downsampling = DefaultDownsamplingMode
 	}
-
-	d, err := newDecoder(reader)
-	if err != nil {
-		return nil, err
+	if options.Scale == ScaleEmpty {
+		options.Scale = DefaultScale
+	}
+	if options.DBFloor == 0 {
+		options.DBFloor = DefaultDBFloor
+	}
+	if options.Gamma == 0 {
+		options.Gamma = DefaultGamma
+	}
+	streaming := options.Streaming || d.Length() < 0
+	chunks := options.Chunks
+	blockSamples := 0
+	if options.ChunkDuration > 0 {
+		sr, ok := d.(decoder.SampleRater)
+		if !ok || sr.SampleRate() <= 0 {
+			return nil, ErrUnknownSampleRate
+		}
+		durationSamples := int(math.Round(options.ChunkDuration.Seconds() * float64(sr.SampleRate())))
+		if durationSamples < 1 {
+			durationSamples = 1
+		}
+		if streaming {
+			// the number of chunks is only known once the entire source is read
+			blockSamples = durationSamples
+			chunks = 0
+		} else {
+			chunks = int(math.Round(float64(d.Length()/decoder.FrameWidth) / float64(durationSamples)))
+			if chunks < 1 {
+				chunks = 1
+			}
+		}
+	}
+	chunkSize := 0
+	samplesPerChunk := 0
+	if !streaming {
+		chunkSize = d.Length() / chunks
+		samplesPerChunk = (chunkSize / decoder.FrameWidth) / int(options.Precision)
+	}
+	if options.Channels == ChannelEmpty {
+		options.Channels = DefaultChannelMode
+	}
+	channels := 1
+	if options.Channels == ChannelSplitStereo {
+		channels = 2
+	}
+	aggregators := []Aggregator{options.Aggregator}
+	for _, layer := range options.Layers {
+		duplicate := false
+		for _, a := range aggregators {
+			duplicate = duplicate || a == layer
+		}
+		if !duplicate {
+			aggregators = append(aggregators, layer)
+		}
+	}
+	layers := make([][][]float64, len(aggregators))
+	lower := make([][][]float64, len(aggregators))
+	signed := false
+	for a := range layers {
+		layers[a] = make([][]float64, channels)
+		for c := range layers[a] {
+			layers[a][c] = make([]float64, chunks)
+		}
+		if aggregators[a] == AggregatorMinMax {
+			signed = true
+			lower[a] = make([][]float64, channels)
+			for c := range lower[a] {
+				lower[a][c] = make([]float64, chunks)
+			}
+		}
+	}
+	sampleRate := func() (int, error) {
+		sr, ok := d.(decoder.SampleRater)
+		if !ok || sr.SampleRate() <= 0 {
+			return 0, ErrUnknownSampleRate
+		}
+		return sr.SampleRate(), nil
+	}
+	// contiguous is set when an analysis requires reading all samples in order, e.g., because
+	// its filters or frames span chunks
+	contiguous := false
+	var meters []*loudnessMeter
+	for _, a := range aggregators {
+		if loudnessWindow(a) < 0 || meters != nil {
+			continue
+		}
+		sr, err := sampleRate()
+		if err != nil {
+			return nil, err
+		}
+		meters = make([]*loudnessMeter, channels)
+		for c := range meters {
+			meters[c] = newLoudnessMeter(sr, options.Channels, c)
+		}
+		contiguous = true
+	}
+	var analyzer *bandAnalyzer
+	var bands []Bands
+	if options.Bands {
+		sr, err := sampleRate()
+		if err != nil {
+			return nil, err
+		}
+		analyzer = newBandAnalyzer(sr)
+		bands = make([]Bands, chunks)
+	}
+	var spectrogram *stft
+	if options.Spectrogram != nil {
+		sr, err := sampleRate()
+		if err != nil {
+			return nil, err
+		}
+		spectrogram, err = newSTFT(sr, options.Spectrogram)
+		if err != nil {
+			return nil, err
+		}
+		contiguous = true
+	}
+	if contiguous {
+		options.Precision = PrecisionFull
+		options.Downsampling = DownsamplingNone
+		if !streaming {
+			samplesPerChunk = chunkSize / decoder.FrameWidth
+		}
 	}
-
-	chunkSize := int(d.length()) / options.Chunks
-	blocks := make([]float64, options.Chunks)
-	samplesPerChunk := (chunkSize / DefaultGoMp3FrameWidth) / int(options.Precision)
 	singleSampleBuffer := make([][2]float64, 1)
 
 	ctx := &ReaderContext{
-		chunks:             options.Chunks,
+		chunks:             chunks,
 		mode:               options.Aggregator,
-		reader:             reader,
 		decoder:            d,
-		blocks:             blocks,
+		aggregators:        aggregators,
+		layers:             layers,
+		lower:              lower,
+		signed:             signed,
+		meters:             meters,
+		analyzer:           analyzer,
+		bands:              bands,
+		stft:               spectrogram,
+		scale:              options.Scale,
+		dbFloor:            options.DBFloor,
+		gamma:              options.Gamma,
+		channelMode:        options.Channels,
 		chunkSize:          chunkSize,
 		precision:          options.Precision,
 		samplesPerChunk:    samplesPerChunk,
@@ -226,10 +616,15 @@ func New(options *ReaderOptions, reader io.Reader) (*ReaderContext, error) {
 		windowAlgo:         options.Window.Algorithm,
 		clipping:           options.Clamping,
 		normalize:          options.Normalize,
+		reference:          options.Reference,
+		streaming:          streaming,
+		blockSamples:       blockSamples,
+	}
+	if !streaming {
+		ctx.samples = int64(d.Length() / decoder.FrameWidth)
 	}
 
-	err = ctx.process()
-	if err != nil {
+	if err := ctx.process(); err != nil {
 		return nil, err
 	}
 
@@ -241,7 +636,115 @@ func (r *ReaderContext) Close() {
 }
 
 func (r *ReaderContext) Blocks() []float64 {
-	return r.blocks
+	return r.layers[0][0]
+}
+
+// RightBlocks returns the blocks of the right channel when using ChannelSplitStereo, in which
+// case Blocks returns the blocks of the left channel. RightBlocks returns nil for all other
+// channel modes.
+func (r *ReaderContext) RightBlocks() []float64 {
+	if len(r.layers[0]) < 2 {
+		return nil
+	}
+	return r.layers[0][1]
+}
+
+// LowerEnvelope returns the lower envelope of the signal when ReaderOptions.Aggregator is
+// AggregatorMinMax, in which case Blocks returns the upper envelope. Both are signed, i.e., in
+// [-1,1]. For ChannelSplitStereo, the envelope is that of the left channel. LowerEnvelope
+// returns nil for all other aggregators.
+func (r *ReaderContext) LowerEnvelope() []float64 {
+	if r.lower[0] == nil {
+		return nil
+	}
+	return r.lower[0][0]
+}
+
+// RightLowerEnvelope returns the lower envelope of the right channel when using
+// ChannelSplitStereo and AggregatorMinMax, in which case RightBlocks returns the upper envelope.
+// RightLowerEnvelope returns nil for all other channel modes and aggregators.
+func (r *ReaderContext) RightLowerEnvelope() []float64 {
+	if r.lower[0] == nil || len(r.lower[0]) < 2 {
+		return nil
+	}
+	return r.lower[0][1]
+}
+
+// Bands returns the energy of the frequency bands of each chunk when ReaderOptions.Bands is
+// set, and nil otherwise. Bands are computed from the first series of the channel mode, i.e.,
+// the left channel for ChannelSplitStereo.
+func (r *ReaderContext) Bands() []Bands {
+	return r.bands
+}
+
+// Spectrogram returns the spectrogram of the source with a column for each chunk when
+// ReaderOptions.Spectrogram is set, and nil otherwise. Like Bands, it is computed from the
+// first series of the channel mode.
+func (r *ReaderContext) Spectrogram() *Spectrogram {
+	return r.spectrogram
+}
+
+// Layers returns the blocks of each aggregator computed in a single pass, i.e., of
+// ReaderOptions.Aggregator and all of ReaderOptions.Layers. For ChannelSplitStereo, the blocks
+// are those of the left channel, see RightLayers.
+func (r *ReaderContext) Layers() map[Aggregator][]float64 {
+	return r.channelLayers(0)
+}
+
+// RightLayers returns the blocks of each aggregator for the right channel when using
+// ChannelSplitStereo, and nil for all other channel modes
+func (r *ReaderContext) RightLayers() map[Aggregator][]float64 {
+	if len(r.layers[0]) < 2 {
+		return nil
+	}
+	return r.channelLayers(1)
+}
+
+func (r *ReaderContext) channelLayers(channel int) map[Aggregator][]float64 {
+	layers := make(map[Aggregator][]float64, len(r.aggregators))
+	for a, aggregator := range r.aggregators {
+		layers[aggregator] = r.layers[a][channel]
+	}
+	return layers
+}
+
+// Metadata returns information on the audio source, such as its sample rate and duration, for
+// labeling waveforms or synchronizing them with audio players
+func (r *ReaderContext) Metadata() *Metadata {
+	m := &Metadata{
+		Samples: r.samples,
+		Chunks:  r.chunks,
+	}
+	if sr, ok := r.decoder.(decoder.SampleRater); ok {
+		m.SampleRate = sr.SampleRate()
+	}
+	if c, ok := r.decoder.(decoder.ChannelCounter); ok {
+		m.Channels = c.Channels()
+	}
+	if m.SampleRate > 0 {
+		m.Duration = time.Duration(r.samples) * time.Second / time.Duration(m.SampleRate)
+	}
+	switch {
+	case !r.streaming:
+		m.SamplesPerChunk = r.chunkSize / decoder.FrameWidth
+	case r.blockSamples > 0:
+		m.SamplesPerChunk = r.blockSamples
+	case r.chunks > 0:
+		m.SamplesPerChunk = int(r.samples / int64(r.chunks))
+	}
+	return m
+}
+
+// Levels returns the range of all series after scaling and before normalization, which serves
+// as ReaderOptions.Reference for normalizing a batch of sources jointly
+func (r *ReaderContext) Levels() Levels {
+	return r.levels
+}
+
+// Chunks returns the number of blocks, which differs from ReaderOptions.Chunks when the
+// number of chunks is derived from ReaderOptions.ChunkDuration
+func (r *ReaderContext) Chunks() int {
+	return r.chunks
 }
 
 func (r *ReaderContext) process() error {
@@ -252,75 +755,242 @@ func (r *ReaderContext) process() error {
 	// 	Int("total samples", int(r.decoder.length())).
 	// 	Send()
 
-	blockBuffer := make([][2]float64, r.samplesPerChunk)
-	for i := range r.blocks {
-		var err error
-		switch r.downsampling {
-		case DownsamplingHead:
-			_, err = r.downsampleHead(blockBuffer)
-		case DownsamplingCenter:
-			_, err = r.downsampleCenter(blockBuffer, i)
-		case DownsamplingTail:
-			_, err = r.downsampleTail(blockBuffer)
-		case DownsamplingNone:
-			_, err = r.decoder.read(blockBuffer)
-		default:
-			return fmt.Errorf("downsampling mode %s is not supported", r.downsampling)
+	var err error
+	switch {
+	case r.streaming && r.blockSamples > 0:
+		err = r.aggregateFixed()
+	case r.streaming:
+		err = r.aggregateStreaming()
+	default:
+		err = r.aggregateChunks()
+	}
+	if err != nil {
+		return err
+	}
+	if r.stft != nil {
+		r.spectrogram = r.stft.spectrogram(r.chunks)
+	}
+
+	for _, series := range r.allSeries() {
+		r.scaleSeries(series)
+	}
+
+	r.levels = r.measureLevels()
+
+	// last step is to normalize the block range to [0,1]. All series are normalized jointly
+	// to retain the balance between channels and the relation between layers
+	if r.normalize {
+		levels := r.levels
+		if r.reference != nil {
+			levels = *r.reference
 		}
-		if err != nil {
-			return err
+		r.normalizeLevels(levels)
+	}
+	for a, layer := range r.layers {
+		for c := range layer {
+			if r.lower[a] == nil {
+				layer[c] = r.shape(layer[c], r.clipping.Min, r.clipping.Max)
+				continue
+			}
+			// envelopes are signed, so they are clamped symmetrically
+			layer[c] = r.shape(layer[c], -r.clipping.Max, r.clipping.Max)
+			r.lower[a][c] = r.shape(r.lower[a][c], -r.clipping.Max, r.clipping.Max)
 		}
+	}
 
-		monoSignal := toMono(blockBuffer)
-
-		block := float64(0)
-		switch r.mode {
-		case AggregatorMax:
-			block = max(monoSignal)
-		case AggregatorAverage:
-			block = mean(monoSignal)
-		case AggregatorRoundedAverage:
-			block = roundedMean(monoSignal, DefaultRoundingPrecision)
-		case AggregatorMeanSquare:
-			block = meanSquare(monoSignal)
-		case AggregatorRootMeanSquare:
-			block = rootMeanSquare(monoSignal)
-		default:
-			return fmt.Errorf("mode %s is not implemented", r.mode)
+	return nil
+}
+
+// scaleSeries maps the amplitude of each block of a series in place. Signed blocks of envelopes
+// retain their sign.
+func (r *ReaderContext) scaleSeries(series []float64) {
+	for idx, sample := range series {
+		switch r.scale {
+		case ScaleDecibels:
+			series[idx] = math.Copysign(decibels(math.Abs(sample), r.dbFloor), sample)
+		case ScalePower:
+			series[idx] = math.Copysign(math.Pow(math.Abs(sample), r.gamma), sample)
 		}
-		r.blocks[i] = block
 	}
+}
 
-	// last step is to normalize the block range to [0,1]
-	if r.normalize {
-		r.blocks = normalize(r.blocks)
+// allSeries returns the series of all aggregators and channels, including lower envelopes
+func (r *ReaderContext) allSeries() [][]float64 {
+	var all [][]float64
+	for a, layer := range r.layers {
+		all = append(all, layer...)
+		if r.lower[a] != nil {
+			all = append(all, r.lower[a]...)
+		}
 	}
-	for idx, sample := range r.blocks {
-		r.blocks[idx] = clamp(sample, r.clipping.Min, r.clipping.Max)
+	return all
+}
+
+// measureLevels determines the range of the unsigned series and the peak of the signed
+// envelopes of AggregatorMinMax
+func (r *ReaderContext) measureLevels() Levels {
+	levels := Levels{Min: math.Inf(1), Max: math.Inf(-1)}
+	for a, layer := range r.layers {
+		for c, series := range layer {
+			if r.lower[a] == nil {
+				levels.Min = math.Min(levels.Min, min(series))
+				levels.Max = math.Max(levels.Max, max(series))
+				continue
+			}
+			for _, sample := range series {
+				levels.Peak = math.Max(levels.Peak, math.Abs(sample))
+			}
+			for _, sample := range r.lower[a][c] {
+				levels.Peak = math.Max(levels.Peak, math.Abs(sample))
+			}
+		}
+	}
+	return levels
+}
+
+// normalizeLevels scales the unsigned series from [levels.Min, levels.Max] to [0,1], and the
+// signed envelopes of AggregatorMinMax by levels.Peak, such that they span at most [-1,1]
+// while retaining the zero line
+func (r *ReaderContext) normalizeLevels(levels Levels) {
+	for a, layer := range r.layers {
+		for c, series := range layer {
+			if r.lower[a] == nil {
+				for idx, sample := range series {
+					series[idx] = (sample - levels.Min) / (levels.Max - levels.Min)
+				}
+				continue
+			}
+			if levels.Peak == 0 {
+				continue
+			}
+			for _, envelope := range [][]float64{series, r.lower[a][c]} {
+				for idx := range envelope {
+					envelope[idx] /= levels.Peak
+				}
+			}
+		}
+	}
+}
+
+// shape clamps a series of blocks and applies the window function
+func (r *ReaderContext) shape(series []float64, min float64, max float64) []float64 {
+	for idx, sample := range series {
+		series[idx] = clamp(sample, min, max)
 	}
 
 	switch r.windowAlgo {
 	case Hann:
-		r.blocks = hann(r.blocks, r.windowParam)
+		return hann(series, r.windowParam)
 	case Tukey:
-		r.blocks = tukey(r.blocks, r.windowParam)
+		return tukey(series, r.windowParam)
 	case PlanckTaper:
-		r.blocks = planck_taper(r.blocks, r.windowParam)
+		return planck_taper(series, r.windowParam)
 	case Rectangular:
 		// rectangular window over the entire range equals no window
 	default:
 	}
+	return series
+}
 
+// aggregateChunks reads the downsampled samples of each chunk of a source of known length and
+// aggregates them to blocks
+func (r *ReaderContext) aggregateChunks() error {
+	blockBuffer := make([][2]float64, r.samplesPerChunk)
+	for i := range r.layers[0][0] {
+		var err error
+		n := len(blockBuffer)
+		switch r.downsampling {
+		case DownsamplingHead:
+			n, err = r.downsampleHead(blockBuffer)
+		case DownsamplingCenter:
+			n, err = r.downsampleCenter(blockBuffer, i)
+		case DownsamplingTail:
+			n, err = r.downsampleTail(blockBuffer)
+		case DownsamplingNone:
+			n, err = r.decoder.Read(blockBuffer)
+		default:
+			return fmt.Errorf("downsampling mode %s is not supported", r.downsampling)
+		}
+		if err != nil {
+			return err
+		}
+
+		// short reads leave samples of the previous chunk in the tail of the buffer
+		samples := blockBuffer[:n]
+		var signed [][]float64
+		if r.signed || r.analyzer != nil || r.stft != nil {
+			signed = toSignedChannels(samples, r.channelMode)
+		}
+		if r.analyzer != nil {
+			// chunks are not contiguous when downsampling, so frames do not span chunks
+			for _, sample := range signed[0] {
+				if bands, ok := r.analyzer.add(sample); ok {
+					r.bands[i].add(bands)
+				}
+			}
+			r.bands[i].add(r.analyzer.flush())
+		}
+		if r.stft != nil {
+			for _, sample := range signed[0] {
+				r.stft.add(sample)
+			}
+		}
+		energy := make([]float64, len(r.meters))
+		for c, meter := range r.meters {
+			for _, sample := range samples {
+				energy[c] += meter.add(sample)
+			}
+		}
+		for c, signal := range toChannels(samples, r.channelMode) {
+			for a, mode := range r.aggregators {
+				if w := loudnessWindow(mode); w >= 0 {
+					r.layers[a][c][i] = r.loudness(w, energy[c], n, r.meters[c].window(w))
+					continue
+				}
+				if n == 0 {
+					// chunks past the end of the source are silent
+					continue
+				}
+				if mode == AggregatorMinMax {
+					r.layers[a][c][i] = max(signed[c])
+					r.lower[a][c][i] = min(signed[c])
+					continue
+				}
+				block, err := aggregate(mode, signal)
+				if err != nil {
+					return err
+				}
+				r.layers[a][c][i] = block
+			}
+		}
+	}
 	return nil
 }
 
+// aggregate reduces a signal to a single block with the given aggregator
+func aggregate(mode Aggregator, signal []float64) (float64, error) {
+	switch mode {
+	case AggregatorMax, AggregatorPeak:
+		return max(signal), nil
+	case AggregatorAverage:
+		return mean(signal), nil
+	case AggregatorRoundedAverage:
+		return roundedMean(signal, DefaultRoundingPrecision), nil
+	case AggregatorMeanSquare:
+		return meanSquare(signal), nil
+	case AggregatorRootMeanSquare:
+		return rootMeanSquare(signal), nil
+	}
+	return 0, fmt.Errorf("mode %s is not implemented", mode)
+}
+
 func (r *ReaderContext) downsampleHead(block [][2]float64) (int, error) {
-	n, err := r.decoder.read(block)
+	n, err := r.decoder.Read(block)
 	if err != nil {
 		return n, err
 	}
-	seekSize := r.chunkSize - (n * DefaultGoMp3FrameWidth)
-	_, err = r.decoder.seek(int64(seekSize), io.SeekCurrent)
+	seekSize := r.chunkSize - (n * decoder.FrameWidth)
+	_, err = r.decoder.Seek(int64(seekSize), io.SeekCurrent)
 	if errors.Is(err, io.EOF) {
 		return n, nil
 	}
@@ -329,36 +999,36 @@ func (r *ReaderContext) downsampleHead(block [][2]float64) (int, error) {
 
 func (r *ReaderContext) downsampleTail(block [][2]float64) (int, error) {
 	n := len(block)
-	seekSize := r.chunkSize - (n * DefaultGoMp3FrameWidth)
-	sb, err := r.decoder.seek(int64(seekSize), io.SeekCurrent)
+	seekSize := r.chunkSize - (n * decoder.FrameWidth)
+	_, err := r.decoder.Seek(int64(seekSize), io.SeekCurrent)
 	if errors.Is(err, io.EOF) {
-		return int(sb) / r.decoder.width, nil
+		return 0, nil
 	}
 	if err != nil {
 		return 0, err
 	}
-	rb, err := r.decoder.read(block)
+	rb, err := r.decoder.Read(block)
 	if errors.Is(err, io.EOF) {
 		return rb, nil
 	}
 	if err != nil {
 		return 0, err
 	}
-	return r.samplesPerChunk, nil
+	return rb, nil
 }
 
 func (r *ReaderContext) downsampleCenter(block [][2]float64, chunk int) (int, error) {
-	n := r.samplesPerChunk * r.decoder.width
+	n := r.samplesPerChunk * decoder.FrameWidth
 	lq := (r.chunkSize / 2) - (n / 2)
 	seekTo := (int64(r.chunkSize*(chunk) + lq))
-	sb, err := r.decoder.seek(seekTo, io.SeekStart)
+	_, err := r.decoder.Seek(seekTo, io.SeekStart)
 	if errors.Is(err, io.EOF) {
-		return int(sb) / r.decoder.width, nil
+		return 0, nil
 	}
 	if err != nil {
 		return 0, err
 	}
-	rb, err := r.decoder.read(block)
+	rb, err := r.decoder.Read(block)
 	if errors.Is(err, io.EOF) {
 		return rb, nil
 	}
@@ -366,12 +1036,12 @@ func (r *ReaderContext) downsampleCenter(block [][2]float64, chunk int) (int, er
 		return 0, err
 	}
 	seekEnd := int64((chunk + 1) * r.chunkSize)
-	sb, err = r.decoder.seek(seekEnd, io.SeekStart)
+	_, err = r.decoder.Seek(seekEnd, io.SeekStart)
 	if errors.Is(err, io.EOF) {
-		return int(sb) / r.decoder.width, nil
+		return rb, nil
 	}
 	if err != nil {
 		return 0, err
 	}
-	return r.samplesPerChunk, nil
+	return rb, nil
 }
@@ -17,12 +17,19 @@ limitations under the License.
 package transform
 
 import (
+	"bytes"
+	"encoding/binary"
 	"errors"
 	"io"
 	"log"
+	"math"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/zoomoid/waveman2/pkg/decoder"
 )
 
 const (
@@ -117,3 +124,794 @@ func TestNew(t *testing.T) {
 	}
 	t.Fatalf("block slice only contains 0 entries, expected at least one non-null sample")
 }
+
+// memoryDecoder implements decoder.Decoder for samples that are already decoded
+type memoryDecoder struct {
+	samples [][2]float64
+	pos     int
+}
+
+func (d *memoryDecoder) Length() int {
+	return len(d.samples) * decoder.FrameWidth
+}
+
+func (d *memoryDecoder) Read(samples [][2]float64) (int, error) {
+	n := copy(samples, d.samples[d.pos:])
+	d.pos += n
+	return n, nil
+}
+
+func (d *memoryDecoder) Decode(p []byte) ([2]float64, int) {
+	return [2]float64{}, decoder.FrameWidth
+}
+
+func (d *memoryDecoder) Seek(offset int64, whence int) (int64, error) {
+	pos := int64(d.pos * decoder.FrameWidth)
+	switch whence {
+	case io.SeekStart:
+		pos = offset
+	case io.SeekCurrent:
+		pos += offset
+	case io.SeekEnd:
+		pos = int64(d.Length()) + offset
+	}
+	d.pos = int(pos) / decoder.FrameWidth
+	if d.pos > len(d.samples) {
+		d.pos = len(d.samples)
+	}
+	return pos, nil
+}
+
+func (d *memoryDecoder) Close() error {
+	return nil
+}
+
+func TestNewFromDecoder(t *testing.T) {
+	// a ramp of 8 chunks with constant amplitude each
+	samples := make([][2]float64, 8*1000)
+	for i := range samples {
+		v := float64(i/1000+1) / 8
+		samples[i] = [2]float64{v, v}
+	}
+	ctx, err := NewFromDecoder(&ReaderOptions{
+		Chunks:     8,
+		Aggregator: AggregatorMax,
+	}, &memoryDecoder{samples: samples})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, block := range ctx.Blocks() {
+		if expected := float64(i+1) / 8; block != expected {
+			t.Errorf("block %d: expected %g, found %g", i, expected, block)
+		}
+	}
+}
+
+// wavFile creates an in-memory 16 bit stereo WAV file of 4 chunks with constant amplitude each
+func wavFile() []byte {
+	const frames = 4 * 1000
+	data := &bytes.Buffer{}
+	for i := 0; i < frames; i++ {
+		v := int16((i/1000 + 1) * 4096)
+		binary.Write(data, binary.LittleEndian, [2]int16{v, v})
+	}
+	out := &bytes.Buffer{}
+	out.WriteString("RIFF")
+	binary.Write(out, binary.LittleEndian, uint32(36+data.Len()))
+	out.WriteString("WAVEfmt ")
+	for _, v := range []any{uint32(16), uint16(1), uint16(2), uint32(44100), uint32(44100 * 4), uint16(4), uint16(16)} {
+		binary.Write(out, binary.LittleEndian, v)
+	}
+	out.WriteString("data")
+	binary.Write(out, binary.LittleEndian, uint32(data.Len()))
+	out.Write(data.Bytes())
+	return out.Bytes()
+}
+
+func TestNewNonSeekable(t *testing.T) {
+	// pipes are not seekable, and the format is sniffed from the content
+	ctx, err := New(&ReaderOptions{
+		Chunks:     4,
+		Aggregator: AggregatorMax,
+	}, io.MultiReader(bytes.NewReader(wavFile())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, block := range ctx.Blocks() {
+		if expected := float64((i+1)*4096) / 32767; block != expected {
+			t.Errorf("block %d: expected %g, found %g", i, expected, block)
+		}
+	}
+}
+
+func TestNewKeepsOptions(t *testing.T) {
+	options := &ReaderOptions{
+		Chunks:     4,
+		Aggregator: AggregatorMax,
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := New(options, bytes.NewReader(wavFile())); err != nil {
+			t.Fatal(err)
+		}
+		// a sniffed format must not leak into the next source read with the same options
+		if options.Format != FormatEmpty {
+			t.Fatalf("expected options.Format to remain empty, found %q", options.Format)
+		}
+	}
+}
+
+// unknownLengthDecoder is a memoryDecoder for a source of unknown length
+type unknownLengthDecoder struct {
+	memoryDecoder
+}
+
+func (d *unknownLengthDecoder) Length() int {
+	return -1
+}
+
+func TestNewStreaming(t *testing.T) {
+	// a ramp of 8 chunks with constant amplitude each
+	samples := make([][2]float64, 8*1000)
+	for i := range samples {
+		v := float64(i/1000+1) / 8
+		samples[i] = [2]float64{v, v}
+	}
+	for name, d := range map[string]decoder.Decoder{
+		"unknown length": &unknownLengthDecoder{memoryDecoder{samples: samples}},
+		"known length":   &memoryDecoder{samples: samples},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx, err := NewFromDecoder(&ReaderOptions{
+				Chunks:     8,
+				Aggregator: AggregatorMax,
+				// streaming is used regardless for sources of unknown length
+				Streaming: name == "known length",
+			}, d)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, block := range ctx.Blocks() {
+				if expected := float64(i+1) / 8; block != expected {
+					t.Errorf("block %d: expected %g, found %g", i, expected, block)
+				}
+			}
+		})
+	}
+}
+
+func TestNewStreamingShortSource(t *testing.T) {
+	samples := [][2]float64{{0.5, 0.5}, {1, 1}}
+	ctx, err := NewFromDecoder(&ReaderOptions{
+		Chunks:     4,
+		Aggregator: AggregatorAverage,
+		Streaming:  true,
+	}, &memoryDecoder{samples: samples})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// fewer samples than chunks repeat samples instead of leaving chunks empty
+	expected := []float64{0.5, 0.5, 1, 1}
+	for i, block := range ctx.Blocks() {
+		if block != expected[i] {
+			t.Errorf("block %d: expected %g, found %g", i, expected[i], block)
+		}
+	}
+}
+
+// eofDecoder is a memoryDecoder of unknown length at 1000 Hz that returns io.EOF along with the
+// last samples, as permitted by the io.Reader convention
+type eofDecoder struct {
+	memoryDecoder
+}
+
+func (d *eofDecoder) Length() int {
+	return -1
+}
+
+func (d *eofDecoder) SampleRate() int {
+	return 1000
+}
+
+func (d *eofDecoder) Read(samples [][2]float64) (int, error) {
+	n, _ := d.memoryDecoder.Read(samples)
+	if d.pos == len(d.samples) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func TestNewStreamingEOF(t *testing.T) {
+	// a ramp of 4 chunks with constant amplitude each, shorter than a single read
+	samples := make([][2]float64, 4*500)
+	for i := range samples {
+		v := float64(i/500+1) / 4
+		samples[i] = [2]float64{v, v}
+	}
+	for name, options := range map[string]*ReaderOptions{
+		"chunks":         {Chunks: 4, Aggregator: AggregatorMax, Streaming: true},
+		"chunk duration": {ChunkDuration: 500 * time.Millisecond, Aggregator: AggregatorMax, Streaming: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx, err := NewFromDecoder(options, &eofDecoder{memoryDecoder{samples: samples}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ctx.Blocks()) != 4 {
+				t.Fatalf("expected 4 blocks, found %d", len(ctx.Blocks()))
+			}
+			for i, block := range ctx.Blocks() {
+				if expected := float64(i+1) / 4; block != expected {
+					t.Errorf("block %d: expected %g, found %g", i, expected, block)
+				}
+			}
+		})
+	}
+}
+
+// sampleRateDecoder is a memoryDecoder that knows the sample rate of its samples
+type sampleRateDecoder struct {
+	memoryDecoder
+	sampleRate int
+}
+
+func (d *sampleRateDecoder) SampleRate() int {
+	return d.sampleRate
+}
+
+func TestChunkDuration(t *testing.T) {
+	// a ramp of 8 seconds at 1000 Hz with constant amplitude each second
+	samples := make([][2]float64, 8*1000)
+	for i := range samples {
+		v := float64(i/1000+1) / 8
+		samples[i] = [2]float64{v, v}
+	}
+
+	ctx, err := NewFromDecoder(&ReaderOptions{
+		Chunks:        64,
+		ChunkDuration: time.Second,
+		Aggregator:    AggregatorMax,
+	}, &sampleRateDecoder{memoryDecoder{samples: samples}, 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Chunks() != 8 || len(ctx.Blocks()) != 8 {
+		t.Fatalf("expected 8 chunks, found %d", ctx.Chunks())
+	}
+	for i, block := range ctx.Blocks() {
+		if expected := float64(i+1) / 8; block != expected {
+			t.Errorf("block %d: expected %g, found %g", i, expected, block)
+		}
+	}
+
+	// streaming chunks span exactly the duration, except for the last one
+	ctx, err = NewFromDecoder(&ReaderOptions{
+		ChunkDuration: 3 * time.Second,
+		Aggregator:    AggregatorMax,
+		Streaming:     true,
+	}, &sampleRateDecoder{memoryDecoder{samples: samples}, 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []float64{3.0 / 8, 6.0 / 8, 1}
+	if ctx.Chunks() != len(expected) {
+		t.Fatalf("expected %d chunks, found %d", len(expected), ctx.Chunks())
+	}
+	for i, block := range ctx.Blocks() {
+		if block != expected[i] {
+			t.Errorf("block %d: expected %g, found %g", i, expected[i], block)
+		}
+	}
+
+	_, err = NewFromDecoder(&ReaderOptions{ChunkDuration: time.Second}, &memoryDecoder{samples: samples})
+	if !errors.Is(err, ErrUnknownSampleRate) {
+		t.Errorf("expected ErrUnknownSampleRate, found %v", err)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	samples := make([][2]float64, 8*1000)
+	for _, streaming := range []bool{false, true} {
+		ctx, err := NewFromDecoder(&ReaderOptions{
+			Chunks:    8,
+			Streaming: streaming,
+		}, &sampleRateDecoder{memoryDecoder{samples: samples}, 4000})
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := ctx.Metadata()
+		expected := Metadata{
+			SampleRate:      4000,
+			Samples:         8000,
+			Duration:        2 * time.Second,
+			Chunks:          8,
+			SamplesPerChunk: 1000,
+		}
+		if *m != expected {
+			t.Errorf("streaming %t: expected %+v, found %+v", streaming, expected, *m)
+		}
+	}
+}
+
+func TestChannelModes(t *testing.T) {
+	// out-of-phase material with a louder left channel
+	samples := make([][2]float64, 4*1000)
+	for i := range samples {
+		samples[i] = [2]float64{0.5, -0.25}
+	}
+	expected := map[ChannelMode][]float64{
+		ChannelMono:        {0.125},
+		ChannelLeft:        {0.5},
+		ChannelRight:       {0.25},
+		ChannelMid:         {0.125},
+		ChannelSide:        {0.375},
+		ChannelSplitStereo: {0.5, 0.25},
+	}
+	for mode, values := range expected {
+		for _, streaming := range []bool{false, true} {
+			ctx, err := NewFromDecoder(&ReaderOptions{
+				Chunks:     4,
+				Aggregator: AggregatorMax,
+				Channels:   mode,
+				Streaming:  streaming,
+			}, &memoryDecoder{samples: samples})
+			if err != nil {
+				t.Fatal(err)
+			}
+			series := [][]float64{ctx.Blocks()}
+			if right := ctx.RightBlocks(); right != nil {
+				series = append(series, right)
+			}
+			if len(series) != len(values) {
+				t.Fatalf("%s: expected %d series, found %d", mode, len(values), len(series))
+			}
+			for c, blocks := range series {
+				for i, block := range blocks {
+					if block != values[c] {
+						t.Errorf("%s (streaming %t): series %d, block %d: expected %g, found %g", mode, streaming, c, i, values[c], block)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestLayers(t *testing.T) {
+	samples := make([][2]float64, 4*1000)
+	for i := range samples {
+		v := 0.2
+		if i%2 == 1 {
+			v = 0.6
+		}
+		samples[i] = [2]float64{v, v}
+	}
+	expected := map[Aggregator]float64{
+		AggregatorRootMeanSquare: math.Sqrt(0.2),
+		AggregatorPeak:           0.6,
+		AggregatorAverage:        0.4,
+	}
+	for _, streaming := range []bool{false, true} {
+		ctx, err := NewFromDecoder(&ReaderOptions{
+			Chunks:     4,
+			Aggregator: AggregatorRootMeanSquare,
+			// the primary aggregator is only computed once
+			Layers:    []Aggregator{AggregatorPeak, AggregatorRootMeanSquare, AggregatorAverage},
+			Streaming: streaming,
+		}, &memoryDecoder{samples: samples})
+		if err != nil {
+			t.Fatal(err)
+		}
+		layers := ctx.Layers()
+		if len(layers) != len(expected) {
+			t.Fatalf("expected %d layers, found %d", len(expected), len(layers))
+		}
+		for aggregator, value := range expected {
+			for i, block := range layers[aggregator] {
+				if math.Abs(block-value) > 1e-12 {
+					t.Errorf("%s (streaming %t): block %d: expected %g, found %g", aggregator, streaming, i, value, block)
+				}
+			}
+		}
+		if &ctx.Blocks()[0] != &layers[AggregatorRootMeanSquare][0] {
+			t.Error("expected Blocks to return the layer of the primary aggregator")
+		}
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	// asymmetric signal alternating between +0.25*(i+1) and -0.125*(i+1) in chunk i
+	samples := make([][2]float64, 4*1000)
+	for i := range samples {
+		v := 0.25 * float64(i/1000+1)
+		if i%2 == 1 {
+			v = -v / 2
+		}
+		samples[i] = [2]float64{v, v}
+	}
+	for _, streaming := range []bool{false, true} {
+		ctx, err := NewFromDecoder(&ReaderOptions{
+			Chunks:     4,
+			Aggregator: AggregatorMinMax,
+			Streaming:  streaming,
+		}, &memoryDecoder{samples: samples})
+		if err != nil {
+			t.Fatal(err)
+		}
+		upper, lower := ctx.Blocks(), ctx.LowerEnvelope()
+		if len(lower) != 4 {
+			t.Fatalf("expected lower envelope of 4 blocks, found %d", len(lower))
+		}
+		for i := range upper {
+			if expected := 0.25 * float64(i+1); upper[i] != expected {
+				t.Errorf("streaming %t: upper block %d: expected %g, found %g", streaming, i, expected, upper[i])
+			}
+			if expected := -0.125 * float64(i+1); lower[i] != expected {
+				t.Errorf("streaming %t: lower block %d: expected %g, found %g", streaming, i, expected, lower[i])
+			}
+		}
+	}
+
+	// normalization scales envelopes by their peak, retaining the zero line
+	ctx, err := NewFromDecoder(&ReaderOptions{
+		Chunks:     4,
+		Aggregator: AggregatorMinMax,
+		Normalize:  true,
+	}, &memoryDecoder{samples: samples})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upper, lower := ctx.Blocks()[3], ctx.LowerEnvelope()[3]; upper != 1 || lower != -0.5 {
+		t.Errorf("expected normalized envelope [1 -0.5], found [%g %g]", upper, lower)
+	}
+
+	// split stereo yields envelopes of both channels, with the right channel inverted here
+	stereo := make([][2]float64, len(samples))
+	for i, s := range samples {
+		stereo[i] = [2]float64{s[0], -s[1]}
+	}
+	ctx, err = NewFromDecoder(&ReaderOptions{
+		Chunks:     4,
+		Aggregator: AggregatorMinMax,
+		Channels:   ChannelSplitStereo,
+	}, &memoryDecoder{samples: stereo})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upper, lower := ctx.RightBlocks()[3], ctx.RightLowerEnvelope()[3]; upper != 0.5 || lower != -1 {
+		t.Errorf("expected right envelope [0.5 -1], found [%g %g]", upper, lower)
+	}
+	if upper, lower := ctx.Blocks()[3], ctx.LowerEnvelope()[3]; upper != 1 || lower != -0.5 {
+		t.Errorf("expected left envelope [1 -0.5], found [%g %g]", upper, lower)
+	}
+
+	ctx, err = NewFromDecoder(&ReaderOptions{Chunks: 4}, &memoryDecoder{samples: samples})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.LowerEnvelope() != nil || ctx.RightLowerEnvelope() != nil {
+		t.Error("expected no lower envelope for aggregators other than min-max")
+	}
+}
+
+// paddedDecoder reports a length exceeding its samples, as estimated by decoders of
+// compressed formats, such that the last chunk is read short
+type paddedDecoder struct {
+	memoryDecoder
+	padding int
+}
+
+func (d *paddedDecoder) Length() int {
+	return d.memoryDecoder.Length() + d.padding*decoder.FrameWidth
+}
+
+func TestMinMaxShortRead(t *testing.T) {
+	// 3.5 chunks of decreasing amplitude, alternating between +a and -a/2 in each chunk
+	samples := make([][2]float64, 3*1000+500)
+	for i := range samples {
+		v := 1 - 0.25*float64(i/1000)
+		if i%2 == 1 {
+			v = -v / 2
+		}
+		samples[i] = [2]float64{v, v}
+	}
+	ctx, err := NewFromDecoder(&ReaderOptions{
+		Chunks:     4,
+		Aggregator: AggregatorMinMax,
+	}, &paddedDecoder{memoryDecoder: memoryDecoder{samples: samples}, padding: 500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the last chunk only covers the samples read, not those of the previous chunk
+	if upper, lower := ctx.Blocks()[3], ctx.LowerEnvelope()[3]; upper != 0.25 || lower != -0.125 {
+		t.Errorf("expected last block [0.25 -0.125], found [%g %g]", upper, lower)
+	}
+
+	ctx, err = NewFromDecoder(&ReaderOptions{
+		Chunks:     4,
+		Aggregator: AggregatorMax,
+	}, &paddedDecoder{memoryDecoder: memoryDecoder{samples: samples}, padding: 500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block := ctx.Blocks()[3]; block != 0.25 {
+		t.Errorf("expected last block 0.25, found %g", block)
+	}
+
+	// chunks past the end of the source are silent
+	ctx, err = NewFromDecoder(&ReaderOptions{
+		Chunks:     4,
+		Aggregator: AggregatorAverage,
+	}, &paddedDecoder{memoryDecoder: memoryDecoder{samples: samples[:2000]}, padding: 2000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block := ctx.Blocks()[3]; block != 0 {
+		t.Errorf("expected silent last block, found %g", block)
+	}
+}
+
+func TestLoudness(t *testing.T) {
+	// a full-scale 997 Hz sine in both channels has a loudness of 0 LUFS
+	sampleRate := 48000
+	samples := make([][2]float64, 4*sampleRate)
+	for i := range samples {
+		v := math.Sin(2 * math.Pi * 997 * float64(i) / float64(sampleRate))
+		samples[i] = [2]float64{v, v}
+	}
+	for _, streaming := range []bool{false, true} {
+		for _, aggregator := range []Aggregator{AggregatorLoudnessMomentary, AggregatorLoudnessShortTerm} {
+			ctx, err := NewFromDecoder(&ReaderOptions{
+				Chunks:     4,
+				Aggregator: aggregator,
+				Streaming:  streaming,
+			}, &sampleRateDecoder{memoryDecoder{samples: samples}, sampleRate})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, block := range ctx.Blocks() {
+				if lufs := 20 * math.Log10(block); math.Abs(lufs) > 0.1 {
+					t.Errorf("streaming %t: %s block %d: expected 0 LUFS, found %g", streaming, aggregator, i, lufs)
+				}
+			}
+		}
+	}
+
+	// a single channel is 3 dB quieter, which maps to 0.95 with a floor of -60 LUFS
+	ctx, err := NewFromDecoder(&ReaderOptions{
+		Chunks:     4,
+		Aggregator: AggregatorLoudnessMomentary,
+		Channels:   ChannelLeft,
+		Scale:      ScaleDecibels,
+	}, &sampleRateDecoder{memoryDecoder{samples: samples}, sampleRate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block := ctx.Blocks()[3]; math.Abs(block-(1-3.01/60)) > 0.002 {
+		t.Errorf("expected left channel at %g, found %g", 1-3.01/60, block)
+	}
+
+	_, err = NewFromDecoder(&ReaderOptions{Aggregator: AggregatorLoudnessMomentary}, &memoryDecoder{samples: samples})
+	if err != ErrUnknownSampleRate {
+		t.Errorf("expected %v without sample rate, found %v", ErrUnknownSampleRate, err)
+	}
+}
+
+func TestLoudnessSilence(t *testing.T) {
+	// full-scale noise followed by digital silence drives running window sums below zero
+	sampleRate := 44100
+	samples := make([][2]float64, 4*sampleRate)
+	rng := rand.New(rand.NewSource(1))
+	for i := range samples[:2*sampleRate] {
+		v := float64(rng.Intn(65535)-32767) / 32767
+		samples[i] = [2]float64{v, v}
+	}
+	for _, streaming := range []bool{false, true} {
+		for _, aggregator := range []Aggregator{AggregatorLoudnessMomentary, AggregatorLoudnessShortTerm} {
+			ctx, err := NewFromDecoder(&ReaderOptions{
+				Chunks:     16,
+				Aggregator: aggregator,
+				Streaming:  streaming,
+			}, &sampleRateDecoder{memoryDecoder{samples: samples}, sampleRate})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, block := range ctx.Blocks() {
+				if math.IsNaN(block) || math.IsInf(block, 0) {
+					t.Errorf("streaming %t: %s block %d: expected finite block, found %g", streaming, aggregator, i, block)
+				}
+			}
+		}
+	}
+
+	if lufs := Loudness(-1e-18); lufs != LoudnessFloor {
+		t.Errorf("expected %g LUFS for negative energy, found %g", LoudnessFloor, lufs)
+	}
+}
+
+func TestKWeighting(t *testing.T) {
+	// BS.1770 specifies the filter coefficients at 48 kHz
+	k := newKWeighting(48000)
+	for _, c := range []struct {
+		name     string
+		actual   float64
+		expected float64
+	}{
+		{"shelf b0", k.shelf.b0, 1.53512485958697},
+		{"shelf b1", k.shelf.b1, -2.69169618940638},
+		{"shelf b2", k.shelf.b2, 1.19839281085285},
+		{"shelf a1", k.shelf.a1, -1.69065929318241},
+		{"shelf a2", k.shelf.a2, 0.73248077421585},
+		{"highpass a1", k.highpass.a1, -1.99004745483398},
+		{"highpass a2", k.highpass.a2, 0.99007225036621},
+	} {
+		if math.Abs(c.actual-c.expected) > 1e-6 {
+			t.Errorf("%s: expected %.14f, found %.14f", c.name, c.expected, c.actual)
+		}
+	}
+}
+
+func TestScale(t *testing.T) {
+	// chunks of constant amplitude 1, 0.1, 0.01, and 0.001, i.e., 0, -20, -40, and -60 dBFS
+	samples := make([][2]float64, 4*100)
+	for i := range samples {
+		v := math.Pow(10, -float64(i/100))
+		samples[i] = [2]float64{v, v}
+	}
+	for _, c := range []struct {
+		options  ReaderOptions
+		expected []float64
+	}{
+		{ReaderOptions{Scale: ScaleLinear}, []float64{1, 0.1, 0.01, 0.001}},
+		{ReaderOptions{Scale: ScaleDecibels}, []float64{1, 2.0 / 3, 1.0 / 3, 0}},
+		{ReaderOptions{Scale: ScaleDecibels, DBFloor: -40}, []float64{1, 0.5, 0, 0}},
+		{ReaderOptions{Scale: ScalePower, Gamma: 0.5}, []float64{1, math.Sqrt(0.1), 0.1, math.Sqrt(0.001)}},
+		// scaling happens before normalization
+		{ReaderOptions{Scale: ScaleDecibels, DBFloor: -80, Normalize: true}, []float64{1, 2.0 / 3, 1.0 / 3, 0}},
+	} {
+		c.options.Chunks = 4
+		c.options.Aggregator = AggregatorMax
+		ctx, err := NewFromDecoder(&c.options, &memoryDecoder{samples: samples})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, block := range ctx.Blocks() {
+			if math.Abs(block-c.expected[i]) > 1e-9 {
+				t.Errorf("scale %s: block %d: expected %g, found %g", c.options.Scale, i, c.expected[i], block)
+			}
+		}
+	}
+}
+
+func TestReference(t *testing.T) {
+	samples := make([][2]float64, 2*100)
+	for i := range samples {
+		v := 0.25 * float64(i/100+1)
+		samples[i] = [2]float64{v, v}
+	}
+	ctx, err := NewFromDecoder(&ReaderOptions{
+		Chunks:     2,
+		Aggregator: AggregatorMax,
+		Layers:     []Aggregator{AggregatorMinMax},
+	}, &memoryDecoder{samples: samples})
+	if err != nil {
+		t.Fatal(err)
+	}
+	levels := ctx.Levels()
+	if levels != (Levels{Min: 0.25, Max: 0.5, Peak: 0.5}) {
+		t.Errorf("expected levels {0.25 0.5 0.5}, found %v", levels)
+	}
+
+	// normalizing a single source against its own levels is the same as normalizing it
+	// individually
+	normalize := func(reference *Levels) *ReaderContext {
+		ctx, err := NewFromDecoder(&ReaderOptions{
+			Chunks:     2,
+			Aggregator: AggregatorMax,
+			Layers:     []Aggregator{AggregatorMinMax},
+			Normalize:  true,
+			Reference:  reference,
+		}, &memoryDecoder{samples: samples})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ctx
+	}
+	individual, joint := normalize(nil), normalize(&levels)
+	for _, layer := range []Aggregator{AggregatorMax, AggregatorMinMax} {
+		for i, block := range individual.Layers()[layer] {
+			if joint.Layers()[layer][i] != block {
+				t.Errorf("layer %s: block %d: expected %g, found %g", layer, i, block, joint.Layers()[layer][i])
+			}
+		}
+	}
+	if blocks := joint.Blocks(); blocks[0] != 0 || blocks[1] != 1 {
+		t.Errorf("expected blocks [0 1], found %v", blocks)
+	}
+
+	// normalizing against the levels of a batch with a louder source retains the relative level
+	batch := levels.Merge(Levels{Min: 0, Max: 1, Peak: 1})
+	if blocks := normalize(&batch).Blocks(); blocks[0] != 0.25 || blocks[1] != 0.5 {
+		t.Errorf("expected blocks [0.25 0.5], found %v", blocks)
+	}
+}
+
+func TestBands(t *testing.T) {
+	// one second each of a 100 Hz, a 1 kHz, and an 8 kHz sine
+	sampleRate := 48000
+	frequencies := []float64{100, 1000, 8000}
+	samples := make([][2]float64, len(frequencies)*sampleRate)
+	for i := range samples {
+		v := math.Sin(2 * math.Pi * frequencies[i/sampleRate] * float64(i) / float64(sampleRate))
+		samples[i] = [2]float64{v, v}
+	}
+	for _, streaming := range []bool{false, true} {
+		ctx, err := NewFromDecoder(&ReaderOptions{
+			Chunks:    3,
+			Bands:     true,
+			Streaming: streaming,
+		}, &sampleRateDecoder{memoryDecoder{samples: samples}, sampleRate})
+		if err != nil {
+			t.Fatal(err)
+		}
+		bands := ctx.Bands()
+		if len(bands) != 3 {
+			t.Fatalf("streaming %t: expected bands of 3 chunks, found %d", streaming, len(bands))
+		}
+		for i, dominant := range []float64{bands[0].Low, bands[1].Mid, bands[2].High} {
+			if ratio := dominant / bands[i].Total(); ratio < 0.95 {
+				t.Errorf("streaming %t: chunk %d: expected dominant band, found ratio %g in %+v", streaming, i, ratio, bands[i])
+			}
+		}
+	}
+
+	ctx, err := NewFromDecoder(&ReaderOptions{Chunks: 3}, &memoryDecoder{samples: samples})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Bands() != nil {
+		t.Error("expected no bands without ReaderOptions.Bands")
+	}
+}
+
+func TestSpectrogram(t *testing.T) {
+	// a full-scale 1 kHz sine
+	sampleRate := 48000
+	samples := make([][2]float64, sampleRate)
+	for i := range samples {
+		v := math.Sin(2 * math.Pi * 1000 * float64(i) / float64(sampleRate))
+		samples[i] = [2]float64{v, v}
+	}
+	for _, streaming := range []bool{false, true} {
+		for _, scale := range []FrequencyScale{FrequencyLinear, FrequencyLog, FrequencyMel} {
+			ctx, err := NewFromDecoder(&ReaderOptions{
+				Chunks:      4,
+				Streaming:   streaming,
+				Spectrogram: &SpectrogramOptions{Scale: scale, Bins: 32},
+			}, &sampleRateDecoder{memoryDecoder{samples: samples}, sampleRate})
+			if err != nil {
+				t.Fatal(err)
+			}
+			spectrogram := ctx.Spectrogram()
+			if len(spectrogram.Columns) != 4 || len(spectrogram.Frequencies) != 33 {
+				t.Fatalf("expected 4 columns of 32 rows, found %d columns and %d edges", len(spectrogram.Columns), len(spectrogram.Frequencies))
+			}
+			for c, column := range spectrogram.Columns {
+				for b, level := range column {
+					contains := spectrogram.Frequencies[b] <= 1000 && 1000 < spectrogram.Frequencies[b+1]
+					if contains && level < 0.99 {
+						t.Errorf("streaming %t: %s: column %d: expected full scale in row %d, found %g", streaming, scale, c, b, level)
+					}
+					if !contains && spectrogram.Frequencies[b] > 2000 && level > 0.5 {
+						t.Errorf("streaming %t: %s: column %d: expected low level in row %d, found %g", streaming, scale, c, b, level)
+					}
+				}
+			}
+		}
+	}
+
+	_, err := NewFromDecoder(&ReaderOptions{
+		Spectrogram: &SpectrogramOptions{FFTSize: 1000},
+	}, &sampleRateDecoder{memoryDecoder{samples: samples}, sampleRate})
+	if err != ErrFFTSize {
+		t.Errorf("expected %v, found %v", ErrFFTSize, err)
+	}
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transform
+
+import (
+	"errors"
+	"math"
+)
+
+// FrequencyScale determines how frequencies are distributed over the rows of a spectrogram
+type FrequencyScale string
+
+const (
+	// FrequencyLinear distributes frequencies evenly from 0 Hz to the Nyquist frequency
+	FrequencyLinear FrequencyScale = "linear"
+	// FrequencyLog distributes frequencies logarithmically from 20 Hz, or the resolution of
+	// the FFT if coarser, to the Nyquist frequency
+	FrequencyLog FrequencyScale = "log"
+	// FrequencyMel distributes frequencies evenly on the mel scale of perceived pitch
+	FrequencyMel FrequencyScale = "mel"
+	// FrequencyEmpty is used for catching uninitialized frequency scales
+	FrequencyEmpty FrequencyScale = ""
+)
+
+// FrequencyScales contains all supported frequency scales for Cobra flag autocompletion
+var FrequencyScales = []string{"linear", "log", "mel"}
+
+const (
+	DefaultFFTSize              int            = 1024
+	DefaultSpectrogramBins      int            = 64
+	DefaultSpectrogramFloor     float64        = -90
+	DefaultFrequencyScale       FrequencyScale = FrequencyLog
+	DefaultSpectrogramAlgorithm                = Hann
+
+	// logMinFrequency is the lowest frequency of FrequencyLog in Hz
+	logMinFrequency float64 = 20
+)
+
+var ErrFFTSize error = errors.New("FFT size must be a power of two")
+
+// SpectrogramOptions configures the short-time Fourier transform of a spectrogram
+type SpectrogramOptions struct {
+	// FFTSize is the number of samples of each frame, which must be a power of two
+	FFTSize int
+	// Hop is the number of samples between the start of consecutive frames, and defaults to
+	// half of FFTSize
+	Hop int
+	// Window is the window function applied to each frame, and defaults to Hann
+	Window *Window
+	// Scale distributes frequencies over the rows of the spectrogram
+	Scale FrequencyScale
+	// Bins is the number of rows of the spectrogram
+	Bins int
+	// DBFloor is the lowest level in dBFS, which maps to 0. Full scale maps to 1.
+	DBFloor float64
+}
+
+// Spectrogram contains the spectrum of each chunk of a source
+type Spectrogram struct {
+	// Columns contains the levels of each row in [0,1] for each chunk, ordered from the
+	// lowest to the highest frequency. Each row is the peak of the frames within the chunk
+	// and the FFT bins within the row.
+	Columns [][]float64
+	// Frequencies contains the edges of the rows in Hz, i.e., one more than the number of rows
+	Frequencies []float64
+}
+
+// stft computes the short-time Fourier transform of a signal sample by sample, and reduces each
+// frame's power spectrum to the rows of the spectrogram
+type stft struct {
+	options *SpectrogramOptions
+	window  []float64
+	// windowSum is the sum of the window's coefficients, which normalizes the spectrum such
+	// that a full-scale sine yields 0 dBFS
+	windowSum float64
+	edges     []float64
+	// resolution is the frequency difference of FFT bins in Hz
+	resolution float64
+
+	pending []float64
+	buffer  []complex128
+	samples int64
+	// frames contains the row powers of all frames, and positions the sample at each frame's
+	// center
+	frames    [][]float64
+	positions []int64
+}
+
+func newSTFT(sampleRate int, options *SpectrogramOptions) (*stft, error) {
+	if options.FFTSize == 0 {
+		options.FFTSize = DefaultFFTSize
+	}
+	if options.FFTSize < 2 || options.FFTSize&(options.FFTSize-1) != 0 {
+		return nil, ErrFFTSize
+	}
+	if options.Hop <= 0 {
+		options.Hop = options.FFTSize / 2
+	}
+	if options.Window == nil {
+		options.Window = &Window{Algorithm: DefaultSpectrogramAlgorithm}
+	}
+	if options.Scale == FrequencyEmpty {
+		options.Scale = DefaultFrequencyScale
+	}
+	if options.Bins <= 0 {
+		options.Bins = DefaultSpectrogramBins
+	}
+	if options.DBFloor == 0 {
+		options.DBFloor = DefaultSpectrogramFloor
+	}
+
+	s := &stft{
+		options:    options,
+		window:     windowCoefficients(options.Window, options.FFTSize),
+		resolution: float64(sampleRate) / float64(options.FFTSize),
+		pending:    make([]float64, 0, options.FFTSize),
+		buffer:     make([]complex128, options.FFTSize),
+	}
+	s.windowSum = sum(s.window)
+	s.edges = frequencyEdges(options.Scale, options.Bins, s.resolution, float64(sampleRate)/2)
+	return s, nil
+}
+
+// frequencyEdges returns the edges of bins rows spaced evenly on the frequency scale between
+// the lowest frequency of the scale and nyquist
+func frequencyEdges(scale FrequencyScale, bins int, resolution float64, nyquist float64) []float64 {
+	forward, inverse := func(f float64) float64 { return f }, func(x float64) float64 { return x }
+	low := float64(0)
+	switch scale {
+	case FrequencyLog:
+		forward, inverse = math.Log, math.Exp
+		low = math.Max(logMinFrequency, resolution)
+	case FrequencyMel:
+		forward = func(f float64) float64 { return 2595 * math.Log10(1+f/700) }
+		inverse = func(m float64) float64 { return 700 * (math.Pow(10, m/2595) - 1) }
+	}
+	edges := make([]float64, bins+1)
+	lower, upper := forward(low), forward(nyquist)
+	for b := range edges {
+		edges[b] = inverse(lower + float64(b)/float64(bins)*(upper-lower))
+	}
+	return edges
+}
+
+// add adds a sample of the signed signal and transforms a frame whenever it is complete
+func (s *stft) add(sample float64) {
+	s.samples++
+	s.pending = append(s.pending, sample)
+	if len(s.pending) < s.options.FFTSize {
+		return
+	}
+	s.transform(s.samples - int64(s.options.FFTSize/2))
+	// keep the overlap with the next frame
+	hop := s.options.Hop
+	if hop > len(s.pending) {
+		hop = len(s.pending)
+	}
+	s.pending = s.pending[:copy(s.pending, s.pending[hop:])]
+}
+
+// transform computes the row powers of the pending samples, zero-padded to a full frame
+func (s *stft) transform(position int64) {
+	for i := range s.buffer {
+		s.buffer[i] = 0
+		if i < len(s.pending) {
+			s.buffer[i] = complex(s.pending[i]*s.window[i], 0)
+		}
+	}
+	fft(s.buffer)
+
+	bins := s.options.FFTSize / 2
+	power := make([]float64, bins+1)
+	for k := range power {
+		magnitude := 2 * math.Hypot(real(s.buffer[k]), imag(s.buffer[k])) / s.windowSum
+		power[k] = magnitude * magnitude
+	}
+	rows := make([]float64, s.options.Bins)
+	for b := range rows {
+		first := int(math.Ceil(s.edges[b] / s.resolution))
+		last := int(math.Ceil(s.edges[b+1]/s.resolution)) - 1
+		if b == len(rows)-1 {
+			last = bins
+		}
+		if first > last {
+			// rows narrower than the FFT's resolution interpolate between adjacent bins
+			center := 0.5 * (s.edges[b] + s.edges[b+1]) / s.resolution
+			k := int(center)
+			if k >= bins {
+				rows[b] = power[bins]
+				continue
+			}
+			t := center - float64(k)
+			rows[b] = (1-t)*power[k] + t*power[k+1]
+			continue
+		}
+		for k := first; k <= last && k <= bins; k++ {
+			rows[b] = math.Max(rows[b], power[k])
+		}
+	}
+	s.frames = append(s.frames, rows)
+	s.positions = append(s.positions, position)
+}
+
+// spectrogram distributes the frames over the given number of chunks by their position, and
+// maps their peak power in dBFS to [0,1]. Sources shorter than a single frame are transformed
+// zero-padded.
+func (s *stft) spectrogram(chunks int) *Spectrogram {
+	if len(s.frames) == 0 && len(s.pending) > 0 {
+		s.transform(int64(len(s.pending) / 2))
+	}
+	columns := make([][]float64, chunks)
+	for c := range columns {
+		columns[c] = make([]float64, s.options.Bins)
+	}
+	for f, rows := range s.frames {
+		c := 0
+		if s.samples > 0 {
+			c = int(s.positions[f] * int64(chunks) / s.samples)
+		}
+		c = int(clamp(float64(c), 0, float64(chunks-1)))
+		for b, power := range rows {
+			columns[c][b] = math.Max(columns[c][b], power)
+		}
+	}
+	for _, column := range columns {
+		for b, power := range column {
+			column[b] = decibels(math.Sqrt(power), s.options.DBFloor)
+			column[b] = math.Min(1, column[b])
+		}
+	}
+	return &Spectrogram{
+		Columns:     columns,
+		Frequencies: s.edges,
+	}
+}
+
+// windowCoefficients returns the coefficients of a window function of length n
+func windowCoefficients(window *Window, n int) []float64 {
+	ones := make([]float64, n)
+	for i := range ones {
+		ones[i] = 1
+	}
+	switch window.Algorithm {
+	case Hann:
+		return hann(ones, window.P)
+	case Tukey:
+		return tukey(ones, window.P)
+	case PlanckTaper:
+		return planck_taper(ones, window.P)
+	}
+	return ones
+}
@@ -0,0 +1,335 @@
+/*
+Copyright 2022-2023 zoomoid.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transform
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	// streamingBufferSize is the number of samples read from the decoder at once in streaming mode
+	streamingBufferSize int = 4096
+	// streamingResolution is the minimum number of buckets aggregated to a single chunk in
+	// streaming mode. Chunks combine between streamingResolution and 2*streamingResolution
+	// buckets, so higher values reduce the variation in the length of chunks.
+	streamingResolution int = 16
+)
+
+// bucket accumulates the state of all aggregators over a range of samples, such that adjacent
+// buckets can be merged without revisiting their samples
+type bucket struct {
+	sum        float64
+	sumSquares float64
+	max        float64
+	// signedMin and signedMax are the extrema of the signed signal for AggregatorMinMax
+	signedMin float64
+	signedMax float64
+	// energy is the total K-weighted energy for loudness aggregators, and windows is the mean
+	// energy of the meter's sliding windows at the bucket's last sample
+	energy  float64
+	windows [2]float64
+	// bands is the spectral energy of all frames completed within the bucket
+	bands Bands
+	n     int
+}
+
+// add adds a sample of the visual signal and the corresponding sample of the signed signal
+func (b *bucket) add(sample float64, signed float64) {
+	if b.n == 0 || sample > b.max {
+		b.max = sample
+	}
+	if b.n == 0 || signed > b.signedMax {
+		b.signedMax = signed
+	}
+	if b.n == 0 || signed < b.signedMin {
+		b.signedMin = signed
+	}
+	b.sum += sample
+	b.sumSquares += sample * sample
+	b.n++
+}
+
+func (b *bucket) merge(o *bucket) {
+	if o.n == 0 {
+		return
+	}
+	if b.n == 0 || o.max > b.max {
+		b.max = o.max
+	}
+	if b.n == 0 || o.signedMax > b.signedMax {
+		b.signedMax = o.signedMax
+	}
+	if b.n == 0 || o.signedMin < b.signedMin {
+		b.signedMin = o.signedMin
+	}
+	b.sum += o.sum
+	b.sumSquares += o.sumSquares
+	b.energy += o.energy
+	b.windows = o.windows
+	b.bands.add(o.bands)
+	b.n += o.n
+}
+
+// aggregate computes the aggregator over all samples of the bucket. Empty buckets yield 0.
+// For AggregatorMinMax, aggregate returns the upper envelope, see bucket.signedMin for the
+// lower envelope.
+func (b *bucket) aggregate(mode Aggregator) (float64, error) {
+	if b.n == 0 {
+		return 0, nil
+	}
+	switch mode {
+	case AggregatorMinMax:
+		return b.signedMax, nil
+	case AggregatorMax, AggregatorPeak:
+		return b.max, nil
+	case AggregatorAverage:
+		return b.sum / float64(b.n), nil
+	case AggregatorRoundedAverage:
+		roundingPrecision := math.Pow(10, float64(DefaultRoundingPrecision))
+		return math.Round(b.sum/float64(b.n)*roundingPrecision) / roundingPrecision, nil
+	case AggregatorMeanSquare:
+		return b.sumSquares / float64(b.n), nil
+	case AggregatorRootMeanSquare:
+		return math.Sqrt(b.sumSquares / float64(b.n)), nil
+	}
+	return 0, fmt.Errorf("mode %s is not implemented", mode)
+}
+
+// aggregateBucket computes the aggregator over all samples of the bucket, including loudness
+// aggregators, which depend on the length of the meter's windows
+func (r *ReaderContext) aggregateBucket(b *bucket, mode Aggregator) (float64, error) {
+	if w := loudnessWindow(mode); w >= 0 {
+		if b.n == 0 {
+			return 0, nil
+		}
+		return r.loudness(w, b.energy, b.n, b.windows[w]), nil
+	}
+	return b.aggregate(mode)
+}
+
+// measure feeds a sample to the loudness meter of each channel, if any, and accumulates its
+// energy in the current buckets
+func (r *ReaderContext) measure(current []bucket, sample [2]float64) {
+	for c, meter := range r.meters {
+		current[c].energy += meter.add(sample)
+	}
+}
+
+// analyze feeds a sample of the first series' signed signal to the band analyzer and the STFT,
+// if any, and adds the energy of completed band frames to the current bucket of the first series
+func (r *ReaderContext) analyze(current []bucket, sample float64) {
+	if r.stft != nil {
+		r.stft.add(sample)
+	}
+	if r.analyzer == nil {
+		return
+	}
+	if bands, ok := r.analyzer.add(sample); ok {
+		current[0].bands.add(bands)
+	}
+}
+
+// snapshot records the meters' sliding windows at the last sample of the current buckets
+func (r *ReaderContext) snapshot(current []bucket) {
+	for c, meter := range r.meters {
+		current[c].windows = [2]float64{meter.window(0), meter.window(1)}
+	}
+}
+
+// aggregateStreaming aggregates the source to blocks in a single pass without knowing its
+// length, using progressive bucket merging: samples are collected in buckets of equal size, and
+// whenever the number of buckets reaches twice the target, adjacent buckets are merged and
+// the bucket size doubles. Finally, the remaining buckets are distributed evenly over the chunks.
+//
+// All samples are read, so precision and downsampling mode do not apply.
+func (r *ReaderContext) aggregateStreaming() error {
+	target := r.chunks * streamingResolution
+	// buckets holds the buckets of each channel, which are always of equal length. Buckets
+	// accumulate the state of all aggregators, so layers share them.
+	buckets := make([][]bucket, len(r.layers[0]))
+	for c := range buckets {
+		buckets[c] = make([]bucket, 0, 2*target)
+	}
+	current := make([]bucket, len(buckets))
+	bucketSize := 1
+	pending := 0
+
+	buffer := make([][2]float64, streamingBufferSize)
+	for {
+		n, err := r.decoder.Read(buffer)
+		r.samples += int64(n)
+		signals := toChannels(buffer[:n], r.channelMode)
+		signed := toSignedChannels(buffer[:n], r.channelMode)
+		for k := 0; k < n; k++ {
+			for c, signal := range signals {
+				current[c].add(signal[k], signed[c][k])
+			}
+			r.measure(current, buffer[k])
+			r.analyze(current, signed[0][k])
+			pending++
+			if pending < bucketSize {
+				continue
+			}
+			r.snapshot(current)
+			for c := range buckets {
+				buckets[c] = append(buckets[c], current[c])
+				current[c] = bucket{}
+			}
+			pending = 0
+			if len(buckets[0]) == 2*target {
+				for c := range buckets {
+					for i := 0; i < target; i++ {
+						buckets[c][2*i].merge(&buckets[c][2*i+1])
+						buckets[c][i] = buckets[c][2*i]
+					}
+					buckets[c] = buckets[c][:target]
+				}
+				bucketSize *= 2
+			}
+		}
+		// decoders may return io.EOF along with the last samples, which are processed above
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			break
+		}
+	}
+	if r.analyzer != nil && len(buckets[0])+pending > 0 {
+		// the incomplete last frame belongs to the last bucket
+		bands := r.analyzer.flush()
+		if pending > 0 {
+			current[0].bands.add(bands)
+		} else {
+			buckets[0][len(buckets[0])-1].bands.add(bands)
+		}
+	}
+	if pending > 0 {
+		r.snapshot(current)
+		for c := range buckets {
+			buckets[c] = append(buckets[c], current[c])
+		}
+	}
+	if len(buckets[0]) == 0 {
+		return nil
+	}
+
+	for c := range buckets {
+		m := len(buckets[c])
+		for i := 0; i < r.chunks; i++ {
+			start := i * m / r.chunks
+			end := (i + 1) * m / r.chunks
+			// sources shorter than the number of chunks repeat samples
+			if end <= start {
+				end = start + 1
+			}
+			chunk := bucket{}
+			for j := start; j < end; j++ {
+				chunk.merge(&buckets[c][j])
+			}
+			if c == 0 && r.bands != nil {
+				r.bands[i] = chunk.bands
+			}
+			for a, mode := range r.aggregators {
+				block, err := r.aggregateBucket(&chunk, mode)
+				if err != nil {
+					return err
+				}
+				r.layers[a][c][i] = block
+				if r.lower[a] != nil {
+					r.lower[a][c][i] = chunk.signedMin
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// aggregateFixed aggregates the source to blocks of blockSamples samples each in a single pass,
+// appending blocks as the source is read. The last block covers the remainder of the source.
+func (r *ReaderContext) aggregateFixed() error {
+	current := make([]bucket, len(r.layers[0]))
+	pending := 0
+	flush := func() error {
+		r.snapshot(current)
+		for c := range current {
+			for a, mode := range r.aggregators {
+				block, err := r.aggregateBucket(&current[c], mode)
+				if err != nil {
+					return err
+				}
+				r.layers[a][c] = append(r.layers[a][c], block)
+				if r.lower[a] != nil {
+					r.lower[a][c] = append(r.lower[a][c], current[c].signedMin)
+				}
+			}
+			if c == 0 && r.analyzer != nil {
+				r.bands = append(r.bands, current[c].bands)
+			}
+			current[c] = bucket{}
+		}
+		pending = 0
+		return nil
+	}
+
+	buffer := make([][2]float64, streamingBufferSize)
+	for {
+		n, err := r.decoder.Read(buffer)
+		r.samples += int64(n)
+		signals := toChannels(buffer[:n], r.channelMode)
+		signed := toSignedChannels(buffer[:n], r.channelMode)
+		for k := 0; k < n; k++ {
+			for c, signal := range signals {
+				current[c].add(signal[k], signed[c][k])
+			}
+			r.measure(current, buffer[k])
+			r.analyze(current, signed[0][k])
+			pending++
+			if pending == r.blockSamples {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		// decoders may return io.EOF along with the last samples, which are processed above
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			break
+		}
+	}
+	if pending > 0 {
+		if r.analyzer != nil {
+			current[0].bands.add(r.analyzer.flush())
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+	}
+	r.chunks = len(r.layers[0][0])
+	return nil
+}
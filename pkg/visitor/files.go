@@ -25,6 +25,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/zoomoid/waveman2/pkg/decoder/registry"
 	"github.com/zoomoid/waveman2/pkg/streams"
 )
 
@@ -32,10 +33,21 @@ const (
 	pathNotExistError string = "the path %q does not exist"
 )
 
-var SupportedFileExtensions = []string{".mp3"}
+// SupportedFileExtensions returns the file extensions of all formats in the decoder registry,
+// including those registered after initialization
+func SupportedFileExtensions() []string {
+	return registry.Extensions()
+}
 
 const (
-	DefaultSVGExtension string = ".svg"
+	DefaultSVGExtension  string = ".svg"
+	DefaultPNGExtension  string = ".png"
+	DefaultJSONExtension string = ".json"
+	DefaultDatExtension  string = ".dat"
+	// StdinPath is the path that denotes reading audio from the standard input stream
+	StdinPath string = "-"
+	// stdinFilename is the bare filename used for naming the output file of audio read from stdin
+	stdinFilename string = "stdin"
 )
 
 type File struct {
@@ -43,6 +55,7 @@ type File struct {
 	filename  string
 	dir       string
 	extension string
+	format    string
 	output    string
 
 	reader io.Reader
@@ -60,6 +73,24 @@ func (f *File) Reader() io.Reader {
 	return f.reader
 }
 
+// Source returns the path the file was read from, or StdinPath for the standard input stream
+func (f *File) Source() string {
+	return f.source
+}
+
+// Extension returns the source file's extension including the leading dot,
+// e.g. ".mp3"
+func (f *File) Extension() string {
+	return f.extension
+}
+
+// Format returns the name of the source's audio format in the decoder registry, detected from
+// the file's magic bytes or, if those are not recognized, from its extension. Format returns
+// an empty string if neither identifies a registered format.
+func (f *File) Format() string {
+	return f.format
+}
+
 // expandPaths transforms all filename flag arguments into fileVisitors,
 // and combines them in a VisitorList wrapper type
 //
@@ -72,6 +103,10 @@ func ExpandPaths(paths []string, recursive bool, useStdout bool, io *streams.IO)
 	vl := NewVisitorList(nil, io)
 
 	for _, s := range paths {
+		if s == StdinPath {
+			vl.File(recursive, useStdout, s)
+			continue
+		}
 		matches, err := expandIfGlob(s)
 		if err != nil {
 			errs = append(errs, err)
@@ -82,8 +117,10 @@ func ExpandPaths(paths []string, recursive bool, useStdout bool, io *streams.IO)
 	return vl, errs
 }
 
-// ignoreFile is a filter function that skips all files with
-// non-matching file extensions (i.e. no mp3 files)
+// ignoreFile is a filter function that skips all files that are no supported
+// audio files, i.e., files that neither have one of the given extensions nor
+// start with the magic bytes of a registered format. Only files with other or
+// missing extensions are opened for sniffing.
 func ignoreFile(path string, extensions []string) bool {
 	if len(extensions) == 0 {
 		return false
@@ -94,7 +131,28 @@ func ignoreFile(path string, extensions []string) bool {
 			return false
 		}
 	}
-	return true
+	f, err := os.Open(path)
+	if err != nil {
+		return true
+	}
+	defer f.Close()
+	_, _, err = registry.Sniff(f)
+	return err != nil
+}
+
+// detectFormat determines the registered format of a source from its magic bytes,
+// and falls back to the file extension. The source is rewound after sniffing.
+func detectFormat(f io.ReadSeeker, ext string) string {
+	if format, _, err := registry.Sniff(f); err == nil {
+		return format.Name
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return ""
+	}
+	if format, err := registry.FromExtension(ext); err == nil {
+		return format.Name
+	}
+	return ""
 }
 
 // expandIfGlob attempts to expand a pattern and returns either a list of
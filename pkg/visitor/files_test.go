@@ -15,3 +15,107 @@ limitations under the License.
 */
 
 package visitor
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/zoomoid/waveman2/pkg/decoder/registry"
+)
+
+func TestIgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]struct {
+		content string
+		ignored bool
+	}{
+		// supported extensions are accepted without reading the file
+		"track.mp3": {"", false},
+		// content hashes without extension are detected by their magic bytes
+		"3f2a9c": {"fLaC\x00\x00\x00\x22", false},
+		"README": {"lorem ipsum", true},
+		// files with other extensions are detected by their magic bytes as well
+		"upload.bin": {"RIFF\x24\x00\x00\x00WAVEfmt ", false},
+		"notes.txt":  {"lorem ipsum", true},
+	}
+	for name, f := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(f.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, f := range files {
+		if ignored := ignoreFile(filepath.Join(dir, name), SupportedFileExtensions()); ignored != f.ignored {
+			t.Errorf("%s: expected ignored to be %t, found %t", name, f.ignored, ignored)
+		}
+	}
+
+	visitors, err := expandPathsToFileVisitors(dir, false, SupportedFileExtensions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(visitors) != 3 {
+		t.Errorf("expected 3 visitors, found %d", len(visitors))
+	}
+
+	// explicitly named files are never ignored
+	visitors, err = expandPathsToFileVisitors(filepath.Join(dir, "notes.txt"), false, SupportedFileExtensions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(visitors) != 1 {
+		t.Errorf("expected 1 visitor for an explicitly named file, found %d", len(visitors))
+	}
+}
+
+func TestRegisteredExtensions(t *testing.T) {
+	// formats registered by library users after initialization are visited as well
+	registry.Register(&registry.Format{
+		Name:       "aiff",
+		Extensions: []string{".aiff"},
+	})
+	if !slices.Contains(SupportedFileExtensions(), ".aiff") {
+		t.Errorf("expected .aiff in supported file extensions, found %v", SupportedFileExtensions())
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "take.aiff"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	visitors, err := expandPathsToFileVisitors(dir, false, SupportedFileExtensions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(visitors) != 1 {
+		t.Errorf("expected 1 visitor, found %d", len(visitors))
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recording.mp3")
+	// wrong extension for an ogg stream
+	if err := os.WriteFile(path, []byte("OggS\x00\x02"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if format := detectFormat(f, ".mp3"); format != "ogg" {
+		t.Errorf("expected format ogg, found %s", format)
+	}
+	// the file is rewound for the decoder
+	if pos, _ := f.Seek(0, io.SeekCurrent); pos != 0 {
+		t.Errorf("expected file to be rewound, found position %d", pos)
+	}
+
+	if err := os.WriteFile(path, []byte("unknown"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if format := detectFormat(f, ".mp3"); format != "mp3" {
+		t.Errorf("expected extension fallback mp3, found %s", format)
+	}
+}
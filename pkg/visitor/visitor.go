@@ -17,6 +17,7 @@ limitations under the License.
 package visitor
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -25,6 +26,7 @@ import (
 	"path/filepath"
 	"regexp"
 
+	"github.com/zoomoid/waveman2/pkg/decoder/registry"
 	"github.com/zoomoid/waveman2/pkg/streams"
 )
 
@@ -32,6 +34,10 @@ import (
 // adding them to the visitorList wrapper type and records any errors encountered.
 func (vl *VisitorList) File(recursive bool, useStdout bool, paths ...string) *VisitorList {
 	for _, p := range paths {
+		if p == StdinPath {
+			vl.visitors = append(vl.visitors, fileVisitor{path: p})
+			continue
+		}
 		_, err := os.Stat(p)
 		if errors.Is(err, fs.ErrNotExist) {
 			vl.errors = append(vl.errors, fmt.Errorf(pathNotExistError, p))
@@ -41,7 +47,7 @@ func (vl *VisitorList) File(recursive bool, useStdout bool, paths ...string) *Vi
 			continue
 		}
 
-		visitors, err := expandPathsToFileVisitors(p, recursive, SupportedFileExtensions)
+		visitors, err := expandPathsToFileVisitors(p, recursive, SupportedFileExtensions())
 		if err != nil {
 			vl.errors = append(vl.errors, fmt.Errorf("error reading %q: %w", p, err))
 		}
@@ -49,7 +55,7 @@ func (vl *VisitorList) File(recursive bool, useStdout bool, paths ...string) *Vi
 	}
 
 	if len(vl.visitors) == 0 && len(vl.errors) == 0 {
-		vl.errors = append(vl.errors, fmt.Errorf("error reading %v: supported file extensions are %v", paths, SupportedFileExtensions))
+		vl.errors = append(vl.errors, fmt.Errorf("error reading %v: supported file extensions are %v", paths, SupportedFileExtensions()))
 	}
 
 	return vl
@@ -69,6 +75,7 @@ type VisitorList struct {
 	continueOnError bool
 	errors          []error
 	useStdout       bool
+	extension       string
 	io              *streams.IO
 }
 
@@ -76,7 +83,7 @@ func NewVisitorList(visitors []fileVisitor, io *streams.IO) *VisitorList {
 	if io == nil {
 		io = streams.DefaultStreams
 	}
-	return &VisitorList{visitors: visitors, io: io}
+	return &VisitorList{visitors: visitors, io: io, extension: DefaultSVGExtension}
 }
 
 // ContinueOnError sets the continueOnError flag to true, meaning
@@ -93,13 +100,73 @@ func (v *VisitorList) UseStdout(useStdout bool) *VisitorList {
 	return v
 }
 
+// OutputExtension sets the extension of output files created next to the audio files,
+// DefaultSVGExtension by default
+func (v *VisitorList) OutputExtension(extension string) *VisitorList {
+	v.extension = extension
+	return v
+}
+
 // Visit is the canonic Visit implementation for a list of Visitors
 // Returns an error on the first error when ContinueOnError is not
 // called beforehand, otherwise aggregates all errors in the list of errors
 // and returns nil
 func (v *VisitorList) Visit(fn VisitorFunc) error {
 	for _, visitor := range v.visitors {
-		err := visitor.visit(v.useStdout, v.io, fn)
+		err := visitor.visit(v.useStdout, v.extension, v.io, fn)
+		if err != nil {
+			if !v.continueOnError {
+				return err
+			}
+			v.errors = append(v.errors, err)
+		}
+	}
+	return nil
+}
+
+// VisitBatch visits all Visitors in two passes: the first pass calls prepare on each file
+// without writing any output, e.g., to compute a reference level shared by all files, and the
+// second pass calls fn like Visit. Stdin is buffered in memory, such that it can be read twice.
+// Errors are handled as in Visit, and files failing the first pass are skipped in the second.
+func (v *VisitorList) VisitBatch(prepare VisitorFunc, fn VisitorFunc) error {
+	in := v.io.In
+	for _, visitor := range v.visitors {
+		if visitor.path != StdinPath {
+			continue
+		}
+		b, err := io.ReadAll(v.io.In)
+		if err != nil {
+			return err
+		}
+		in = bytes.NewReader(b)
+		break
+	}
+	replay := func() io.Reader {
+		if r, ok := in.(*bytes.Reader); ok {
+			r.Seek(0, io.SeekStart)
+		}
+		return in
+	}
+
+	dryRun := &streams.IO{In: replay(), Out: io.Discard, ErrOut: v.io.ErrOut}
+	failed := make([]bool, len(v.visitors))
+	for i, visitor := range v.visitors {
+		err := visitor.visit(true, v.extension, dryRun, prepare)
+		if err != nil {
+			if !v.continueOnError {
+				return err
+			}
+			v.errors = append(v.errors, err)
+			failed[i] = true
+		}
+	}
+
+	out := &streams.IO{In: replay(), Out: v.io.Out, ErrOut: v.io.ErrOut}
+	for i, visitor := range v.visitors {
+		if failed[i] {
+			continue
+		}
+		err := visitor.visit(v.useStdout, v.extension, out, fn)
 		if err != nil {
 			if !v.continueOnError {
 				return err
@@ -122,8 +189,12 @@ func (v *VisitorList) Len() int {
 
 // Visit implements the Visitor interface for fileVisitors by instantiating a File
 // struct with all the required data from the source filename and whether to use
-// stdout as a writer
-func (v *fileVisitor) visit(useStdout bool, streams *streams.IO, fn VisitorFunc) error {
+// stdout as a writer. Output files are named by the source file with the given extension.
+func (v *fileVisitor) visit(useStdout bool, extension string, streams *streams.IO, fn VisitorFunc) error {
+	if v.path == StdinPath {
+		return v.visitStdin(useStdout, extension, streams, fn)
+	}
+
 	var f *os.File
 	var err error
 	f, err = os.Open(v.path)
@@ -140,33 +211,72 @@ func (v *fileVisitor) visit(useStdout bool, streams *streams.IO, fn VisitorFunc)
 		return err
 	}
 	bare := r.ReplaceAllString(p, "")
-	svgFile := r.ReplaceAllString(p, DefaultSVGExtension)
-	svgPath := filepath.Join(dir, svgFile)
-
-	// open writer to stdout, and only create file if stdout is not selected
-	// for a given fileVisitor
-	var writer io.Writer
-	if !useStdout {
-		w, err := os.Create(svgPath)
-		if err != nil {
-			return err
-		}
-		writer = w
-		defer w.Close()
-	} else {
-		w := streams.Out
-		writer = w
+	outputFile := r.ReplaceAllString(p, extension)
+	outputPath := filepath.Join(dir, outputFile)
+
+	writer, closeWriter, err := openWriter(useStdout, streams, outputPath)
+	if err != nil {
+		return err
 	}
+	defer closeWriter()
 
 	file := &File{
 		source:    v.path,
 		dir:       dir,
 		filename:  bare,
 		extension: ext,
-		output:    svgPath,
+		format:    detectFormat(f, ext),
+		output:    outputPath,
 		reader:    f,
 		writer:    writer,
 	}
 
 	return fn(file)
 }
+
+// visitStdin instantiates a File reading from the input stream. Without a filename
+// to derive it from, the output file is named after stdinFilename in the working
+// directory. Stdin usually is a pipe, so its format can only be detected by sniffing
+// its content, and the File's reader replays the sniffed bytes.
+func (v *fileVisitor) visitStdin(useStdout bool, extension string, streams *streams.IO, fn VisitorFunc) error {
+	outputPath := stdinFilename + extension
+
+	writer, closeWriter, err := openWriter(useStdout, streams, outputPath)
+	if err != nil {
+		return err
+	}
+	defer closeWriter()
+
+	format := ""
+	detected, reader, err := registry.Sniff(streams.In)
+	if err == nil {
+		format = detected.Name
+	} else if !errors.Is(err, registry.ErrUnknownFormat) {
+		return err
+	}
+
+	file := &File{
+		source:   v.path,
+		dir:      ".",
+		filename: stdinFilename,
+		format:   format,
+		output:   outputPath,
+		reader:   reader,
+		writer:   writer,
+	}
+
+	return fn(file)
+}
+
+// openWriter opens writer to stdout, and only creates a file at path if stdout is not
+// selected for a given fileVisitor. The returned function closes the file.
+func openWriter(useStdout bool, streams *streams.IO, path string) (io.Writer, func(), error) {
+	if useStdout {
+		return streams.Out, func() {}, nil
+	}
+	w, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return w, func() { w.Close() }, nil
+}
@@ -18,7 +18,9 @@ package visitor
 
 import (
 	"bytes"
+	"io"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/zoomoid/waveman2/pkg/streams"
@@ -112,3 +114,94 @@ func TestUseStdout(t *testing.T) {
 		t.Fatalf("expected output to contain data, found %s", b.String())
 	}
 }
+
+func TestStdin(t *testing.T) {
+	data := []byte("OggS\x00\x02 audio read from a pipe")
+	s := ioFactory()
+	s.In = io.MultiReader(bytes.NewReader(data))
+
+	vl, errs := ExpandPaths([]string{StdinPath}, false, true, s)
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	err := vl.UseStdout(true).Visit(func(f *File) error {
+		if f.Format() != "ogg" {
+			t.Errorf("expected format ogg, found %s", f.Format())
+		}
+		// the sniffed header is replayed to the decoder
+		b, err := io.ReadAll(f.Reader())
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(b, data) {
+			t.Errorf("expected %q, found %q", data, b)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVisitBatch(t *testing.T) {
+	data := []byte("OggS\x00\x02 audio read from a pipe")
+	s := ioFactory()
+	s.In = io.MultiReader(bytes.NewReader(data))
+
+	vl, errs := ExpandPaths([]string{StdinPath}, false, true, s)
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	passes := 0
+	read := func(f *File) error {
+		passes++
+		b, err := io.ReadAll(f.Reader())
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(b, data) {
+			t.Errorf("pass %d: expected %q, found %q", passes, data, b)
+		}
+		f.Print(bytes.NewBufferString("output"))
+		return nil
+	}
+	err := vl.UseStdout(true).VisitBatch(read, read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if passes != 2 {
+		t.Errorf("expected 2 passes, found %d", passes)
+	}
+	// the first pass does not write any output
+	if out := s.Out.(*bytes.Buffer).String(); out != "output" {
+		t.Errorf("expected output of the second pass only, found %q", out)
+	}
+}
+
+func TestMisnamedFile(t *testing.T) {
+	// a WAV file with an unrelated extension, e.g., from an upload form
+	dir := t.TempDir()
+	data := []byte("RIFF\x24\x00\x00\x00WAVEfmt \x10\x00\x00\x00\x01\x00\x01\x00\x44\xac\x00\x00\x88\x58\x01\x00\x02\x00\x10\x00data\x00\x00\x00\x00")
+	if err := os.WriteFile(filepath.Join(dir, "track.dat"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	vl, errs := ExpandPaths([]string{dir}, false, true, ioFactory())
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	visited := 0
+	err := vl.UseStdout(true).Visit(func(f *File) error {
+		visited++
+		if f.Format() != "wav" {
+			t.Errorf("expected format wav, found %s", f.Format())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if visited != 1 {
+		t.Errorf("expected 1 visited file, found %d", visited)
+	}
+}